@@ -0,0 +1,86 @@
+package repeater
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrTooManyPending returned by CancelableLimited if too many abandoned calls are still running
+var ErrTooManyPending = errors.New("too many pending abandoned calls")
+
+// AbandonLimiter counts calls made by CancelableLimited and still running, abandoned or not, and limits their number.
+// Each call reserves a slot before start and releases it when fn returns, so concurrent callers can't exceed the limit.
+// Should be made by NewAbandonLimiter and shared by the calls limited together, i.e. calls to the same dependency.
+type AbandonLimiter struct {
+	maxPending int64
+	pending    int64
+}
+
+// NewAbandonLimiter makes limiter allowing up to maxPending calls still running, maxPending <= 0 means no limit
+func NewAbandonLimiter(maxPending int) *AbandonLimiter {
+	return &AbandonLimiter{maxPending: int64(maxPending)}
+}
+
+// Pending returns the number of calls still running, including abandoned ones
+func (l *AbandonLimiter) Pending() int {
+	return int(atomic.LoadInt64(&l.pending))
+}
+
+// Cancelable wraps fn, unaware of context, to return ctx.Err() as soon as context is done, without waiting
+// for fn completion. This makes Do responsive to cancellation even with long-running calls.
+// Note: fn can't be interrupted, abandoned call keeps running in its own goroutine till fn returns,
+// i.e. each canceled call leaks a goroutine for the rest of fn's run time.
+func Cancelable(ctx context.Context, fn func() error) func() error {
+	return cancelable(ctx, fn, nil)
+}
+
+// CancelableLimited is Cancelable refusing to start a call with ErrTooManyPending if limiter's maxPending calls
+// are still running, i.e. abandoned calls and calls in progress. Calls counted by the limiter only, providing
+// backpressure for high-churn callers sharing it without affecting others.
+func CancelableLimited(ctx context.Context, fn func() error, limiter *AbandonLimiter) func() error {
+	return cancelable(ctx, fn, limiter)
+}
+
+// cancelable implements Cancelable and CancelableLimited, nil limiter means no limit
+func cancelable(ctx context.Context, fn func() error, limiter *AbandonLimiter) func() error {
+	return func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if limiter != nil && !limiter.reserve() {
+			return ErrTooManyPending
+		}
+		done := make(chan error, 1) // buffered, so abandoned goroutine won't block on send
+		go func() {
+			err := fn()
+			if limiter != nil {
+				atomic.AddInt64(&limiter.pending, -1) // release the slot, whether the call abandoned or not
+			}
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a slot for a new call, false if all maxPending slots taken
+func (l *AbandonLimiter) reserve() bool {
+	if l.maxPending <= 0 {
+		atomic.AddInt64(&l.pending, 1)
+		return true
+	}
+	for {
+		pending := atomic.LoadInt64(&l.pending)
+		if pending >= l.maxPending {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&l.pending, pending, pending+1) {
+			return true
+		}
+	}
+}
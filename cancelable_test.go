@@ -0,0 +1,127 @@
+package repeater
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var completed int32
+	fn := func() error {
+		time.Sleep(500 * time.Millisecond) // long call, not aware of context
+		atomic.AddInt32(&completed, 1)
+		return errors.New("some error")
+	}
+
+	st := time.Now()
+	err := NewDefault(5, time.Millisecond).Do(ctx, Cancelable(ctx, fn))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(st) < 200*time.Millisecond, "returned promptly, took %s", time.Since(st))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&completed), "call abandoned")
+}
+
+func TestCancelableCompleted(t *testing.T) {
+	called := 0
+	fn := Cancelable(context.Background(), func() error {
+		called++
+		if called < 3 {
+			return errors.New("some error")
+		}
+		return nil
+	})
+	require.NoError(t, NewDefault(5, time.Millisecond).Do(context.Background(), fn))
+	assert.Equal(t, 3, called)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called = 0
+	assert.ErrorIs(t, Cancelable(ctx, func() error { called++; return nil })(), context.Canceled)
+	assert.Equal(t, 0, called, "not started with canceled context")
+}
+
+func TestCancelableLimited(t *testing.T) {
+	limiter := NewAbandonLimiter(2)
+	release := make(chan struct{})
+	var started int32
+	fn := func() error {
+		atomic.AddInt32(&started, 1)
+		<-release
+		return nil
+	}
+
+	// abandon two calls, each with its own context
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer cancel()
+			assert.ErrorIs(t, CancelableLimited(ctx, fn, limiter)(), context.DeadlineExceeded)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, limiter.Pending())
+
+	err := CancelableLimited(context.Background(), fn, limiter)()
+	assert.ErrorIs(t, err, ErrTooManyPending, "limit reached")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&started), "not started")
+
+	other := NewAbandonLimiter(2)
+	go func() { _ = CancelableLimited(context.Background(), fn, other)() }()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&started) == 3 }, time.Second, time.Millisecond,
+		"other limiter not affected")
+
+	close(release)
+	require.Eventually(t, func() bool { return limiter.Pending() == 0 },
+		time.Second, time.Millisecond, "abandoned calls completed")
+	assert.NoError(t, CancelableLimited(context.Background(), fn, limiter)())
+	assert.Equal(t, int32(4), atomic.LoadInt32(&started))
+}
+
+func TestCancelableLimitedConcurrent(t *testing.T) {
+	limiter := NewAbandonLimiter(1)
+	release := make(chan struct{})
+	var started, maxPending int32
+	fn := func() error {
+		atomic.AddInt32(&started, 1)
+		if p := int32(limiter.Pending()); p > atomic.LoadInt32(&maxPending) {
+			atomic.StoreInt32(&maxPending, p)
+		}
+		<-release
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	var refused int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if errors.Is(CancelableLimited(ctx, fn, limiter)(), ErrTooManyPending) {
+				atomic.AddInt32(&refused, 1)
+			}
+			assert.LessOrEqual(t, limiter.Pending(), 1)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, limiter.Pending())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&started), "single call started")
+	assert.Equal(t, int32(9), atomic.LoadInt32(&refused), "others refused")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&maxPending))
+
+	close(release)
+	require.Eventually(t, func() bool { return limiter.Pending() == 0 }, time.Second, time.Millisecond)
+}
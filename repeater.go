@@ -6,14 +6,85 @@ package repeater
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-pkgz/repeater/strategy"
 )
 
+// defaults for strategy made by New and SetStrategy if no strategy provided
+const (
+	DefaultRepeats = 10
+	DefaultDelay   = 5 * time.Second
+)
+
+// errors returned by Do, joined with the last error
+var (
+	ErrBudgetExhausted       = errors.New("retry budget exhausted")
+	ErrTooManyDistinctErrors = errors.New("too many distinct errors")
+	ErrTooManyFailures       = errors.New("too many consecutive failures")
+	ErrResultRejected        = errors.New("result rejected by retry predicate")
+	ErrSuccessRateTooLow     = errors.New("success rate too low")
+	ErrSharedLimitReached    = errors.New("shared attempt limit reached")
+	ErrNotDrained            = errors.New("attempts exhausted before work drained")
+)
+
 // Repeater is the main object, should be made by New or NewDefault, embeds strategy
 type Repeater struct {
 	Strategy
+	onGiveUp      func(reason GiveUpReason, err error)
+	budget        Budget
+	shouldRetry   func(attempt int, err error) bool
+	eagerFirst    bool
+	maxDistinct   int
+	maxWork       time.Duration
+	attemptsInErr bool
+	minInterval   time.Duration
+	defaultCtx    context.Context
+	errTransform  func(error) error
+	maxFailures   int
+	onRetry       func(attempt int, err error) bool
+	minRate       float64
+	onFirstFail   func(attempt int, err error)
+	rateWindow    int
+	ctxErrWrap    bool
+	gate          Gate
+	successErrs   []error
+	cleanup       func(attempt int, err error)
+	retryErr      bool
+	probe         func(ctx context.Context) error
+	sharedLimit   *atomic.Int64
+	eagerOwnErr   bool
+	onAttempt     func(attempt int, err error) // reports each attempt, set by DoChan and DoAsync
+	lifetime      *lifetime
+}
+
+// Budget limits retries, usually shared by many repeaters to prevent retry amplification.
+// Withdraw called before each retry (not before the first attempt) and returns false if no retries allowed.
+type Budget interface {
+	Withdraw() bool
+}
+
+// Gate pauses attempts, i.e. during maintenance window. Wait called before each attempt, blocks while
+// the gate is closed and returns nil once it is open, or error if context is done.
+type Gate interface {
+	Wait(ctx context.Context) error
+}
+
+// LifetimeStats contains counters accumulated across all Do calls made by the repeater
+type LifetimeStats struct {
+	TotalAttempts int64 // number of fun calls
+	TotalRuns     int64 // number of Do calls
+	TotalSuccess  int64 // number of Do calls returned no error
+	TotalFailures int64 // number of Do calls returned error
+}
+
+type lifetime struct {
+	sync.Mutex
+	stats LifetimeStats
 }
 
 // Strategy interface for repeater strategy
@@ -21,22 +92,468 @@ type Strategy interface {
 	Start(ctx context.Context) <-chan struct{} // returns channel with repeater ticks
 }
 
-// New repeater with a given strategy. If strategy=nil initializes with FixedDelay 5sec, 10 times.
-func New(strtg strategy.Interface) *Repeater {
-	if strtg == nil {
-		strtg = &strategy.FixedDelay{Repeats: 10, Delay: time.Second * 5}
+// Option func type to set Repeater options
+type Option func(r *Repeater)
+
+// GiveUpReason describes why Do stopped without success
+type GiveUpReason int
+
+// enum of all give-up reasons
+const (
+	ReasonExhausted GiveUpReason = iota // strategy completed all attempts
+	ReasonCritical                      // fun returned one of critical errors
+	ReasonContext                       // context canceled or expired
+)
+
+// String returns name of the reason, suitable for logs and metrics
+func (g GiveUpReason) String() string {
+	switch g {
+	case ReasonExhausted:
+		return "exhausted"
+	case ReasonCritical:
+		return "critical"
+	case ReasonContext:
+		return "context"
+	default:
+		return "unknown"
+	}
+}
+
+// New repeater with a given strategy. If strategy=nil initializes with FixedDelay DefaultDelay (5sec), DefaultRepeats (10) times.
+func New(strtg strategy.Interface, opts ...Option) *Repeater {
+	result := Repeater{lifetime: &lifetime{}}
+	result.SetStrategy(strtg)
+	for _, opt := range opts {
+		opt(&result)
 	}
-	result := Repeater{Strategy: strtg}
 	return &result
 }
 
 // NewDefault makes repeater with FixedDelay strategy
-func NewDefault(repeats int, delay time.Duration) *Repeater {
-	return New(&strategy.FixedDelay{Repeats: repeats, Delay: delay})
+func NewDefault(repeats int, delay time.Duration, opts ...Option) *Repeater {
+	return New(&strategy.FixedDelay{Repeats: repeats, Delay: delay}, opts...)
+}
+
+// NewDeadlineBound makes repeater with fixed delay and unlimited attempts, so the only stop condition is the context,
+// i.e. "retry till the deadline". Do with context never done repeats failing fun forever.
+func NewDeadlineBound(delay time.Duration, opts ...Option) *Repeater {
+	return New(strategy.Func(func(int) time.Duration { return delay }), opts...)
+}
+
+// NewRateLimited makes repeater with attempts paced to rps attempts per second, i.e. for APIs with known rate limit.
+// Attempts aligned to 1s/rps cadence with strategy.Ticker, so the time taken by an attempt counts toward the interval.
+// Non-positive rps means no pacing.
+func NewRateLimited(attempts int, rps float64, opts ...Option) *Repeater {
+	var interval time.Duration
+	if rps > 0 {
+		interval = time.Duration(float64(time.Second) / rps)
+	}
+	return New(&strategy.Ticker{Repeats: attempts, Interval: interval}, opts...)
+}
+
+// SetStrategy replaces repeater's strategy. If strategy=nil sets default FixedDelay, same as New does.
+// Not thread-safe, must not be called while Do is in progress.
+func (r *Repeater) SetStrategy(strtg strategy.Interface) {
+	if strtg == nil {
+		strtg = &strategy.FixedDelay{Repeats: DefaultRepeats, Delay: DefaultDelay}
+	}
+	r.Strategy = strtg
+}
+
+// Schedule returns delays between attempts planned by the strategy, without jitter.
+// Returns nil if strategy doesn't implement strategy.Scheduler.
+func (r *Repeater) Schedule() []time.Duration {
+	if s, ok := r.Strategy.(strategy.Scheduler); ok {
+		return s.Schedule()
+	}
+	return nil
+}
+
+// Describe returns human-readable description of repeater's strategy, with its configuration
+// if strategy implements strategy.Describer, or just strategy's type otherwise.
+func (r *Repeater) Describe() string {
+	if d, ok := r.Strategy.(strategy.Describer); ok {
+		return d.Describe()
+	}
+	return fmt.Sprintf("custom strategy %T", r.Strategy)
+}
+
+// LifetimeStats returns counters accumulated across all Do calls since repeater creation.
+// Repeater not made by New or NewDefault doesn't collect them and returns empty stats.
+func (r *Repeater) LifetimeStats() LifetimeStats {
+	if r.lifetime == nil {
+		return LifetimeStats{}
+	}
+	r.lifetime.Lock()
+	defer r.lifetime.Unlock()
+	return r.lifetime.stats
+}
+
+// RetryRate returns the average number of retries per Do call since repeater creation, i.e. the ratio of attempts
+// beyond the first one to the number of calls. Growing rate signals degrading dependency. Returns 0 if no calls made.
+func (r *Repeater) RetryRate() float64 {
+	stats := r.LifetimeStats()
+	if stats.TotalRuns == 0 {
+		return 0
+	}
+	return float64(stats.TotalAttempts-stats.TotalRuns) / float64(stats.TotalRuns)
+}
+
+// WithOnGiveUp sets a hook called once when Do fails. It gets the reason and the error Do returns:
+// ReasonExhausted if all attempts allowed by strategy, limits or budget used, including the only attempt made
+// with done context by WithSkipInitialContextCheck; ReasonCritical if stopped by critical error, predicate or hook;
+// ReasonContext if context canceled or expired. The hook is never called on success.
+func WithOnGiveUp(fn func(reason GiveUpReason, err error)) Option {
+	return func(r *Repeater) {
+		r.onGiveUp = fn
+	}
+}
+
+// WithBudget sets retry budget consulted before each retry. If budget denies retry Do stops
+// and returns ErrBudgetExhausted joined with the last error.
+func WithBudget(b Budget) Option {
+	return func(r *Repeater) {
+		r.budget = b
+	}
+}
+
+// WithShouldRetry sets a predicate called after each failed attempt with 1-based attempt number and error.
+// Returning false stops Do with this error, reported as ReasonCritical. Critical errors passed to Do
+// terminate without calling the predicate.
+func WithShouldRetry(fn func(attempt int, err error) bool) Option {
+	return func(r *Repeater) {
+		r.shouldRetry = fn
+	}
+}
+
+// WithEagerFirstAttempt guarantees fun called at least once, even if context is done before the first attempt,
+// i.e. already done when Do is called or canceled while waiting for it. Only the first attempt ignores the context
+// and the shared attempt limit, still counted by the limit: if it fails, Do returns the context error, as without
+// this option. Useful for best-effort calls, like final flush on shutdown.
+func WithEagerFirstAttempt() Option {
+	return func(r *Repeater) {
+		r.eagerFirst = true
+	}
+}
+
+// WithSkipInitialContextCheck guarantees fun called at least once, same as WithEagerFirstAttempt.
+// Unlike WithEagerFirstAttempt, if this attempt fails Do returns its error rather than the context error,
+// reported as ReasonExhausted since the only allowed attempt failed.
+// Next attempts honor the context as usual.
+func WithSkipInitialContextCheck() Option {
+	return func(r *Repeater) {
+		r.eagerFirst, r.eagerOwnErr = true, true
+	}
+}
+
+// WithMaxDistinctErrors stops Do once more than k distinct errors (by error message) seen,
+// as a sign of systemic instability. Returns ErrTooManyDistinctErrors joined with the last error.
+func WithMaxDistinctErrors(k int) Option {
+	return func(r *Repeater) {
+		r.maxDistinct = k
+	}
+}
+
+// WithMaxWorkDuration retries a successful call taking longer than d, hoping for a faster response on retry.
+// Slow success is not a failure, it doesn't trigger failure hooks and limits, and if Do stops before the retry made,
+// i.e. no attempts left, budget exhausted or context done, slow success is returned as success.
+func WithMaxWorkDuration(d time.Duration) Option {
+	return func(r *Repeater) {
+		r.maxWork = d
+	}
+}
+
+// WithAttemptCountInError wraps the error returned by failed Do to include number of attempts made,
+// i.e. "after 3 attempts: some error". The original error is still reachable by errors.Is and errors.As.
+func WithAttemptCountInError() Option {
+	return func(r *Repeater) {
+		r.attemptsInErr = true
+	}
+}
+
+// WithMinInterval enforces minimal interval between starts of attempts, regardless of strategy's delays.
+// Prevents hot loop burning CPU if strategy misconfigured with zero delay, like NewDefault(1000, 0).
+func WithMinInterval(d time.Duration) Option {
+	return func(r *Repeater) {
+		r.minInterval = d
+	}
+}
+
+// WithDefaultContext sets context used by DoDefault
+func WithDefaultContext(ctx context.Context) Option {
+	return func(r *Repeater) {
+		r.defaultCtx = ctx
+	}
+}
+
+// WithErrorTransform sets a function applied to each error returned by fun, before matching against critical
+// errors, retry predicates and budget. The transformed error is the one Do returns. Useful to unwrap or normalize
+// errors in one place. Note: transform returning nil turns the failed attempt into success, which is rarely desired.
+func WithErrorTransform(fn func(error) error) Option {
+	return func(r *Repeater) {
+		r.errTransform = fn
+	}
+}
+
+// WithMaxConsecutiveFailures stops Do once k failures in a row happened, returning ErrTooManyFailures joined
+// with the last error. Successful calls reset the counter, which matters for DoDrain only, as for Do it is
+// the same as limiting the number of attempts.
+func WithMaxConsecutiveFailures(k int) Option {
+	return func(r *Repeater) {
+		r.maxFailures = k
+	}
+}
+
+// WithOnRetryDecide sets a hook called after each failed attempt which is going to be retried, i.e. not stopped
+// by critical errors, WithShouldRetry or other limits. The hook returning false stops Do with the last error,
+// reported as ReasonCritical.
+func WithOnRetryDecide(fn func(attempt int, err error) (retry bool)) Option {
+	return func(r *Repeater) {
+		r.onRetry = fn
+	}
+}
+
+// WithMinSuccessRate stops Do once the rate of successful calls among the last window calls drops below rate,
+// returning ErrSuccessRateTooLow joined with the last error. The rate checked after failed calls, once window calls made.
+// Matters for DoDrain with interleaving successes and failures mostly, as Do stops on the first success.
+func WithMinSuccessRate(rate float64, window int) Option {
+	return func(r *Repeater) {
+		r.minRate, r.rateWindow = rate, window
+	}
+}
+
+// WithOnFirstFailure sets a hook called on the first failed attempt of each Do, i.e. when a call starts failing,
+// to signal degradation early. Not called on subsequent failures or if no attempts failed.
+func WithOnFirstFailure(fn func(attempt int, err error)) Option {
+	return func(r *Repeater) {
+		r.onFirstFail = fn
+	}
+}
+
+// WithContextErrorWrap wraps the context error returned by Do on cancellation or expiration with number of attempts
+// made and time spent, i.e. "canceled after 2 attempts (1.3s): context canceled" or "timed out after 3 attempts (5s):
+// context deadline exceeded", to make logs more informative.
+// The context error is still reachable by errors.Is.
+func WithContextErrorWrap() Option {
+	return func(r *Repeater) {
+		r.ctxErrWrap = true
+	}
+}
+
+// WithGate sets a gate waited for before each attempt, to pause retries centrally without canceling Do.
+// Gate's error stops Do with this error, reported as ReasonCritical, or as ReasonContext if context is done.
+// Time spent waiting doesn't count as strategy's delay.
+func WithGate(g Gate) Option {
+	return func(r *Repeater) {
+		r.gate = g
+	}
+}
+
+// WithSuccessErrors sets errors treated as success, like io.EOF at the end of a stream. Error matching any of them
+// by errors.Is completes Do with no error, before matching against critical errors. For DoDrain it means the work is drained.
+func WithSuccessErrors(errs ...error) Option {
+	return func(r *Repeater) {
+		r.successErrs = errs
+	}
+}
+
+// WithCleanup sets a hook called right after each failed attempt, before any decision about retry, to release
+// resources of this attempt. As called before the delay, it is called for the last failed attempt even if Do
+// then terminated by canceled context.
+func WithCleanup(fn func(attempt int, err error)) Option {
+	return func(r *Repeater) {
+		r.cleanup = fn
+	}
+}
+
+// WithRetryError makes failed Do return *RetryError with errors of all attempts, number of attempts and time spent,
+// if all attempts exhausted. Errors of other give-up reasons returned as is.
+func WithRetryError() Option {
+	return func(r *Repeater) {
+		r.retryErr = true
+	}
+}
+
+// RetryError is the error returned by Do with WithRetryError on exhausted attempts, wraps the error Do would return otherwise
+type RetryError struct {
+	Attempts int           // number of attempts made
+	Duration time.Duration // time spent by Do
+	Errors   []error       // errors of failed attempts, in order
+	err      error
+}
+
+// Error returns summary with the wrapped error, like "3 attempts failed in 1.2s: some error"
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%d attempts failed in %s: %v", e.Attempts, e.Duration.Round(time.Millisecond), e.err)
+}
+
+// Unwrap returns the wrapped error, i.e. the last one joined with limit error if any
+func (e *RetryError) Unwrap() error {
+	return e.err
+}
+
+// WithProbe sets a cheap health check called before each attempt, i.e. ping before an expensive call.
+// If the probe fails, fun is not called and the probe's error is the attempt's error.
+func WithProbe(fn func(ctx context.Context) error) Option {
+	return func(r *Repeater) {
+		r.probe = fn
+	}
+}
+
+// WithSharedAttemptLimit sets a counter of attempts left, shared by many repeaters, i.e. by all sub-operations of
+// a request, to prevent retry amplification. Each attempt, including the first one, takes one from the counter,
+// and once it is used up Do stops with ErrSharedLimitReached joined with the last error. The counter can go below zero.
+func WithSharedAttemptLimit(limit *atomic.Int64) Option {
+	return func(r *Repeater) {
+		r.sharedLimit = limit
+	}
 }
 
 // Do repeats fun till no error. Predefined (optional) errors terminate immediately
 func (r Repeater) Do(ctx context.Context, fun func() error, errs ...error) (err error) {
+	return r.run(ctx, func() (bool, error) {
+		e := fun()
+		return e == nil, e
+	}, errs)
+}
+
+// DoDefault is a shortcut for Do with context set by WithDefaultContext, or context.Background() if not set.
+// Do with explicit context is the primary API, this one is a sugar for call sites with context bound at construction.
+func (r Repeater) DoDefault(fun func() error, errs ...error) error {
+	ctx := r.defaultCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return r.Do(ctx, fun, errs...)
+}
+
+// DoTimeout is Do with context derived from parent with timeout. The derived context is canceled on return.
+func (r Repeater) DoTimeout(parent context.Context, timeout time.Duration, fun func() error, errs ...error) error {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	return r.Do(ctx, fun, errs...)
+}
+
+// DoWithFallback repeats primary as Do does and, if all attempts exhausted, calls fallback once and returns its error.
+// Fallback is not called if primary terminated by critical error or context, such errors returned as is.
+func (r Repeater) DoWithFallback(ctx context.Context, primary, fallback func() error, errs ...error) error {
+	exhausted := false
+	hook := r.onGiveUp // r is a copy, hook replaced for this call only
+	r.onGiveUp = func(reason GiveUpReason, err error) {
+		exhausted = reason == ReasonExhausted
+		if hook != nil {
+			hook(reason, err)
+		}
+	}
+	if err := r.Do(ctx, primary, errs...); err == nil || !exhausted {
+		return err
+	}
+	return fallback()
+}
+
+// DoAsync makes the first call of fun in the caller's goroutine and returns the first attempt's error, with retries,
+// if needed, going on in background. The first attempt goes through all the checks and transformations Do makes,
+// so firstErr is nil if Do considers the attempt successful. The done channel delivers the final result, same as Do
+// returns, and closed after it. If Do stopped before the first attempt, i.e. by gate or shared limit, firstErr is
+// Do's result.
+func (r Repeater) DoAsync(ctx context.Context, fun func() error, errs ...error) (firstErr error, done <-chan error) {
+	ch := make(chan error, 1)
+	first := make(chan func())      // the first call of fun, handed over to the caller's goroutine
+	firstRes := make(chan error, 1) // result of the first attempt, after Do's transformations
+	called := false
+	async := func() (err error) { // fun, the first call made by the caller
+		if called {
+			return fun()
+		}
+		called = true
+		made := make(chan struct{})
+		first <- func() {
+			defer close(made)
+			err = fun()
+		}
+		<-made
+		return err
+	}
+	hook := r.onAttempt // r is a copy, hook replaced for this call only
+	r.onAttempt = func(attempt int, err error) {
+		if attempt == 1 {
+			firstRes <- err
+		}
+		if hook != nil {
+			hook(attempt, err)
+		}
+	}
+	go func() {
+		defer close(ch)
+		ch <- r.Do(ctx, async, errs...)
+	}()
+
+	for {
+		select {
+		case call := <-first:
+			call()
+		case err := <-firstRes:
+			return err, ch
+		case err := <-ch: // stopped, with or without attempts
+			res := make(chan error, 1)
+			res <- err
+			close(res)
+			select {
+			case e := <-firstRes: // the first attempt reported before stop
+				return e, res
+			default:
+				return err, res
+			}
+		}
+	}
+}
+
+// DoDrain calls fun while it reports some work done, i.e. till the work is drained and fun returns didWork=false.
+// Each call, with or without work done, takes a tick from the strategy, so strategy's delays apply between calls
+// and strategy's completion stops the loop. If strategy completed while fun still reports work done, DoDrain returns
+// ErrNotDrained. Errors are retried and predefined (optional) errors terminate immediately, same as for Do.
+func (r Repeater) DoDrain(ctx context.Context, fun func() (didWork bool, err error), errs ...error) error {
+	return r.run(ctx, func() (bool, error) {
+		didWork, err := fun()
+		return !didWork, err
+	}, errs)
+}
+
+// DoParallel runs Do for each of funcs, up to concurrency at once, and returns their errors in the same order.
+// All funcs share repeater's strategy, so it has to be safe for concurrent use, as all built-in strategies are.
+// Funcs not started before context done get the context error.
+func (r Repeater) DoParallel(ctx context.Context, concurrency int, funcs []func() error, errs ...error) []error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	res := make([]error, len(funcs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, fun := range funcs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil { // don't start new work on canceled context
+			for j := i; j < len(funcs); j++ {
+				res[j] = ctx.Err()
+			}
+			break
+		}
+		wg.Add(1)
+		go func(i int, fun func() error) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res[i] = r.Do(ctx, fun, errs...)
+		}(i, fun)
+	}
+	wg.Wait()
+	return res
+}
+
+// run is the main loop for Do and its variants. fun returns complete=true if no more calls needed.
+// Call without error and not completed is not a failure, just a signal to continue.
+func (r Repeater) run(ctx context.Context, fun func() (complete bool, err error), errs []error) (err error) {
 	ctx, cancelFunc := context.WithCancel(ctx)
 	defer cancelFunc() // ensure strategy's channel termination
 
@@ -49,21 +566,437 @@ func (r Repeater) Do(ctx context.Context, fun func() error, errs ...error) (err
 		return false
 	}
 
+	call := func() (complete bool, err error) { // single attempt, with optional probe and error transformation
+		if r.probe != nil {
+			err = r.probe(ctx)
+		}
+		if err == nil {
+			complete, err = fun()
+		}
+		if err != nil && r.errTransform != nil {
+			err = r.errTransform(err)
+		}
+		for _, e := range r.successErrs {
+			if err != nil && errors.Is(err, e) { // benign error, completes as success
+				return true, nil
+			}
+		}
+		return complete, err
+	}
+
+	attempts, failures := 0, 0       // failures is the number of consecutive failed attempts
+	failed := false                  // any attempt failed
+	complete, slow := false, false   // slow indicates the last attempt succeeded, but took longer than maxWork
+	var st time.Time                 // start time of the last attempt
+	var distinct map[string]struct{} // error messages seen, made on demand by maxDistinct
+	var outcomes *outcomesWindow     // results of the last calls, made on demand by minRate
+	var attemptErrs []error          // errors of failed attempts, collected for WithRetryError only
+	started := time.Now()
+	defer func() { r.lifetime.add(attempts, err) }()
+
+	eager := func() error { // context done before the first attempt, make the only one
+		if r.sharedLimit != nil {
+			r.sharedLimit.Add(-1) // counted, but not refused by the limit
+		}
+		attempts++
+		complete, err = call()
+		if r.onAttempt != nil {
+			r.onAttempt(attempts, err)
+		}
+		if err == nil && complete {
+			return nil
+		}
+		if err != nil && r.cleanup != nil {
+			r.cleanup(attempts, err)
+		}
+		if err != nil && r.retryErr {
+			attemptErrs = append(attemptErrs, err)
+		}
+		if err != nil && r.onFirstFail != nil {
+			r.onFirstFail(attempts, err)
+		}
+		if err != nil && inErrors(err) {
+			return r.giveUp(ReasonCritical, attempts, started, attemptErrs, err)
+		}
+		if err != nil && r.eagerOwnErr { // the only allowed attempt failed
+			return r.giveUp(ReasonExhausted, attempts, started, attemptErrs, err)
+		}
+		return r.giveUp(ReasonContext, attempts, started, attemptErrs, ctx.Err())
+	}
+
+	stop := func(reason GiveUpReason, err error) error { // stop before the next attempt, keeping slow success
+		if slow {
+			return nil
+		}
+		if reason == ReasonContext && attempts == 0 && r.eagerFirst { // canceled before the first attempt
+			return eager()
+		}
+		return r.giveUp(reason, attempts, started, attemptErrs, err)
+	}
+
+	if r.eagerFirst && ctx.Err() != nil { // context already done
+		return eager()
+	}
+
+	if rs, ok := r.Strategy.(strategy.Resettable); ok {
+		rs.Reset() // clean state left by previous run
+	}
 	ch := r.Start(ctx) // channel of ticks-like events provided by strategy
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return stop(ReasonContext, ctx.Err())
 		case _, ok := <-ch:
 			if !ok { // closed channel indicates completion or early termination, set by strategy
-				return err
+				switch {
+				case ctx.Err() != nil: // strategy terminated because of canceled context
+					return stop(ReasonContext, ctx.Err())
+				case err == nil && attempts > 0: // DoDrain's last call did some work
+					return stop(ReasonExhausted, ErrNotDrained)
+				case err == nil: // no attempts made by strategy
+					return nil
+				}
+				return stop(ReasonExhausted, err)
+			}
+			if ctx.Err() != nil { // both tick and context done were ready, select could pick the tick
+				return stop(ReasonContext, ctx.Err())
+			}
+			if (err != nil || slow) && r.budget != nil && !r.budget.Withdraw() { // retry after failed or slow attempt
+				return stop(ReasonExhausted, errors.Join(ErrBudgetExhausted, err))
+			}
+			if r.sharedLimit != nil && r.sharedLimit.Add(-1) < 0 {
+				return stop(ReasonExhausted, errors.Join(ErrSharedLimitReached, err))
+			}
+			if wait := r.minInterval - time.Since(st); attempts > 0 && wait > 0 {
+				if !sleep(ctx, wait) {
+					return stop(ReasonContext, ctx.Err())
+				}
+			}
+			if r.gate != nil {
+				if e := r.gate.Wait(ctx); e != nil {
+					if ctx.Err() != nil {
+						return stop(ReasonContext, ctx.Err())
+					}
+					return stop(ReasonCritical, e)
+				}
+			}
+			attempts++
+			st = time.Now()
+			complete, err = call()
+			slow = err == nil && complete && r.maxWork > 0 && time.Since(st) > r.maxWork
+			if r.onAttempt != nil {
+				r.onAttempt(attempts, err)
 			}
-			if err = fun(); err == nil {
+			if r.rateWindow > 0 {
+				if outcomes == nil {
+					outcomes = &outcomesWindow{results: make([]bool, r.rateWindow)}
+				}
+				outcomes.add(err == nil)
+			}
+			if err == nil {
+				if complete && !slow {
+					return nil
+				}
+				failures = 0
+				continue
+			}
+			failures++
+			if r.cleanup != nil {
+				r.cleanup(attempts, err)
+			}
+			if r.retryErr {
+				attemptErrs = append(attemptErrs, err)
+			}
+			if !failed && r.onFirstFail != nil {
+				r.onFirstFail(attempts, err)
+			}
+			failed = true
+			if inErrors(err) { // terminate on critical error from provided list
+				return r.giveUp(ReasonCritical, attempts, started, attemptErrs, err)
+			}
+			if r.shouldRetry != nil && !r.shouldRetry(attempts, err) {
+				return r.giveUp(ReasonCritical, attempts, started, attemptErrs, err)
+			}
+			if r.maxDistinct > 0 {
+				if distinct == nil {
+					distinct = map[string]struct{}{}
+				}
+				distinct[err.Error()] = struct{}{}
+				if len(distinct) > r.maxDistinct {
+					return r.giveUp(ReasonCritical, attempts, started, attemptErrs, errors.Join(ErrTooManyDistinctErrors, err))
+				}
+			}
+			if r.maxFailures > 0 && failures >= r.maxFailures {
+				return r.giveUp(ReasonExhausted, attempts, started, attemptErrs, errors.Join(ErrTooManyFailures, err))
+			}
+			if outcomes != nil && outcomes.full() && outcomes.rate() < r.minRate {
+				return r.giveUp(ReasonCritical, attempts, started, attemptErrs, errors.Join(ErrSuccessRateTooLow, err))
+			}
+			if r.onRetry != nil && !r.onRetry(attempts, err) {
+				return r.giveUp(ReasonCritical, attempts, started, attemptErrs, err)
+			}
+		}
+	}
+}
+
+// AttemptEvent describes a single attempt made by DoChan
+type AttemptEvent struct {
+	Attempt   int           // 1-based attempt number
+	Err       error         // attempt's error, for the final event the error Do returned
+	NextDelay time.Duration // nominal delay before the next attempt, 0 if strategy doesn't implement strategy.Scheduler
+	Final     bool          // the last event, sent after Do completed
+}
+
+// DoChan runs Do in background and reports each failed attempt to the returned channel, with the error Do got,
+// i.e. transformed and without success errors. The final event carries the overall result and sent right before
+// the channel closed. The channel is not buffered and has to be drained by consumer, otherwise Do will be blocked.
+// Once context is done, events not accepted by consumer are dropped, so abandoned channel doesn't block Do forever.
+func (r Repeater) DoChan(ctx context.Context, fun func() error, errs ...error) <-chan AttemptEvent {
+	ch := make(chan AttemptEvent)
+	schedule := r.Schedule()
+	send := func(event AttemptEvent) {
+		select { // deliver to waiting consumer even if context is done
+		case ch <- event:
+			return
+		default:
+		}
+		select {
+		case ch <- event:
+		case <-ctx.Done():
+		}
+	}
+	go func() {
+		defer close(ch)
+		attempts := 0
+		r.onAttempt = func(attempt int, err error) { // r is a copy, hook set for this call only
+			attempts = attempt
+			if err == nil {
+				return
+			}
+			event := AttemptEvent{Attempt: attempt, Err: err}
+			if attempt <= len(schedule) {
+				event.NextDelay = schedule[attempt-1]
+			}
+			send(event)
+		}
+		err := r.Do(ctx, fun, errs...)
+		send(AttemptEvent{Attempt: attempts, Err: err, Final: true})
+	}()
+	return ch
+}
+
+// giveUp makes the final error of failed Do and calls onGiveUp hook, if defined
+func (r Repeater) giveUp(reason GiveUpReason, attempts int, started time.Time, attemptErrs []error, err error) error {
+	switch {
+	case r.retryErr && reason == ReasonExhausted && err != nil:
+		err = &RetryError{Attempts: attempts, Duration: time.Since(started), Errors: attemptErrs, err: err}
+	case r.ctxErrWrap && reason == ReasonContext && errors.Is(err, context.Canceled):
+		err = fmt.Errorf("canceled after %d attempts (%s): %w", attempts, time.Since(started).Round(time.Millisecond), err)
+	case r.ctxErrWrap && reason == ReasonContext && errors.Is(err, context.DeadlineExceeded):
+		err = fmt.Errorf("timed out after %d attempts (%s): %w", attempts, time.Since(started).Round(time.Millisecond), err)
+	case r.attemptsInErr && err != nil:
+		err = fmt.Errorf("after %d attempts: %w", attempts, err)
+	}
+	if r.onGiveUp != nil {
+		r.onGiveUp(reason, err)
+	}
+	return err
+}
+
+// add records results of a single Do call, nil-safe
+func (l *lifetime) add(attempts int, err error) {
+	if l == nil {
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	l.stats.TotalRuns++
+	l.stats.TotalAttempts += int64(attempts)
+	if err == nil {
+		l.stats.TotalSuccess++
+		return
+	}
+	l.stats.TotalFailures++
+}
+
+// DoEvery repeats fun till no error, up to attempts times, with a fixed cadence of interval measured from the start.
+// Slow attempts don't drift the schedule, the next attempt starts at the next slot, see strategy.Ticker.
+func DoEvery(ctx context.Context, interval time.Duration, attempts int, fun func() error, errs ...error) error {
+	return New(&strategy.Ticker{Repeats: attempts, Interval: interval}).Do(ctx, fun, errs...)
+}
+
+// DoOnTick repeats fun till no error, up to attempts times. The first attempt goes right away and each next one
+// on the next tick from the channel, i.e. of a time.Ticker shared by many calls to coalesce their wakeups.
+// Ticks received while an attempt is in progress are skipped, see strategy.ExternalTicker.
+func DoOnTick(ctx context.Context, tick <-chan time.Time, attempts int, fun func() error, errs ...error) error {
+	return New(&strategy.ExternalTicker{Repeats: attempts, C: tick}).Do(ctx, fun, errs...)
+}
+
+// DoHedged calls fun up to n times concurrently, starting the next call every spread or right after a failed one,
+// and returns on the first success, canceling context of the calls still in progress. Reduces tail latency of
+// idempotent calls, like reads. Returns all errors joined if every call failed, or context error if it is done first.
+// Calls ignoring the context are abandoned, not waited for.
+func DoHedged(ctx context.Context, n int, spread time.Duration, fun func(ctx context.Context) error) error {
+	if n < 1 {
+		n = 1
+	}
+	ctx, cancel := context.WithCancel(ctx) // canceled on return, stops calls still in progress
+	defer cancel()
+	results := make(chan error, n) // buffered, so abandoned calls won't block on send
+	launched := 0
+	launch := func() {
+		launched++
+		go func() { results <- fun(ctx) }()
+	}
+
+	launch()
+	timer := time.NewTimer(spread)
+	defer timer.Stop()
+	var errs []error
+	for {
+		var next <-chan time.Time // nil if all calls launched
+		if launched < n {
+			next = timer.C
+		}
+		select {
+		case err := <-results:
+			if err == nil {
 				return nil
 			}
-			if err != nil && inErrors(err) { // terminate on critical error from provided list
-				return err
+			errs = append(errs, err)
+			if launched == n {
+				if len(errs) == n {
+					return errors.Join(errs...)
+				}
+				continue
 			}
+			if !timer.Stop() { // fired, but not received
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(spread)
+			launch()
+		case <-next:
+			timer.Reset(spread)
+			launch()
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
 }
+
+// Escalate runs Do of each repeater in order, till one succeeds, modeling tiered policies like a fast repeater
+// first and a slow, patient one next. Returns nil on success or all errors joined if every repeater failed.
+// Stops escalation on done context.
+func Escalate(ctx context.Context, fun func() error, rs ...*Repeater) error {
+	var errs []error
+	for _, r := range rs {
+		err := r.Do(ctx, fun)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Wrap makes a plain func() error repeating fun with r on each call, for pipelines accepting func() error.
+// The context is bound at wrapping time.
+func Wrap(r *Repeater, ctx context.Context, fun func() error, errs ...error) func() error { //nolint:revive // context-as-argument
+	return func() error {
+		return r.Do(ctx, fun, errs...)
+	}
+}
+
+// DoFactory repeats with r a call of use with a resource freshly made by create for each attempt,
+// for one-shot resources like io.Reader which can't be reused after a failed attempt.
+// Failure of either create or use is retried. Resource implementing io.Closer is closed after each use.
+func DoFactory[T any](ctx context.Context, r *Repeater, create func() (T, error), use func(T) error, errs ...error) error {
+	return r.Do(ctx, func() error {
+		res, err := create()
+		if err != nil {
+			return err
+		}
+		if c, ok := any(res).(io.Closer); ok {
+			defer c.Close() //nolint:errcheck // close error doesn't affect the attempt
+		}
+		return use(res)
+	}, errs...)
+}
+
+// DoResult repeats fun with r while it returns error or retryOn reports the result needs retry, like "pending" status.
+// Result rejected by retryOn is a failed attempt with ErrResultRejected error. Returns the last result along with
+// the error, ErrResultRejected if no attempts left while result still rejected.
+func DoResult[T any](ctx context.Context, r *Repeater, fun func() (T, error), retryOn func(T) bool, errs ...error) (T, error) {
+	var res T
+	err := r.Do(ctx, func() (e error) {
+		if res, e = fun(); e != nil {
+			return e
+		}
+		if retryOn(res) {
+			return ErrResultRejected
+		}
+		return nil
+	}, errs...)
+	return res, err
+}
+
+// DoValueN calls fun n times, repeating each call with r till no error, and collects results in order.
+// On failure returns results collected so far along with the error.
+func DoValueN[T any](ctx context.Context, r *Repeater, n int, fun func() (T, error), errs ...error) ([]T, error) {
+	res := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		var val T
+		err := r.Do(ctx, func() (e error) {
+			val, e = fun()
+			return e
+		}, errs...)
+		if err != nil {
+			return res, err
+		}
+		res = append(res, val)
+	}
+	return res, nil
+}
+
+// sleep waits for duration or context done, returns false if context done before duration passed
+func sleep(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// outcomesWindow keeps results of the last len(results) calls, as a ring buffer
+type outcomesWindow struct {
+	results   []bool
+	pos, size int
+	successes int
+}
+
+func (w *outcomesWindow) add(success bool) {
+	if w.size == len(w.results) && w.results[w.pos] { // overwrite the oldest one
+		w.successes--
+	}
+	w.results[w.pos] = success
+	if success {
+		w.successes++
+	}
+	w.pos = (w.pos + 1) % len(w.results)
+	if w.size < len(w.results) {
+		w.size++
+	}
+}
+
+func (w *outcomesWindow) full() bool { return w.size == len(w.results) }
+
+func (w *outcomesWindow) rate() float64 { return float64(w.successes) / float64(w.size) }
@@ -4,7 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -297,3 +302,1737 @@ func TestRepeaterMemoryLeakBackOff(t *testing.T) {
 	after := runtime.NumGoroutine()
 	require.False(t, after > before, "goroutines leak: %+v, before:%d, after:%d", num, before, after)
 }
+
+func TestRepeaterOnGiveUp(t *testing.T) {
+	e := errors.New("some error")
+	criticalErr := errors.New("critical error")
+
+	type giveUp struct {
+		reason GiveUpReason
+		err    error
+	}
+	var calls []giveUp
+	opt := WithOnGiveUp(func(reason GiveUpReason, err error) {
+		calls = append(calls, giveUp{reason: reason, err: err})
+	})
+
+	err := NewDefault(3, time.Millisecond, opt).Do(context.Background(), func() error { return e })
+	assert.Equal(t, e, err)
+	require.Equal(t, 1, len(calls))
+	assert.Equal(t, ReasonExhausted, calls[0].reason)
+	assert.Equal(t, e, calls[0].err)
+
+	calls = nil
+	err = NewDefault(3, time.Millisecond, opt).Do(context.Background(), func() error { return criticalErr }, criticalErr)
+	assert.Equal(t, criticalErr, err)
+	require.Equal(t, 1, len(calls))
+	assert.Equal(t, ReasonCritical, calls[0].reason)
+	assert.Equal(t, criticalErr, calls[0].err)
+
+	calls = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = NewDefault(3, time.Second, opt).Do(ctx, func() error { return e })
+	require.Error(t, err)
+	require.Equal(t, 1, len(calls))
+	assert.Equal(t, ReasonContext, calls[0].reason)
+	assert.Equal(t, err, calls[0].err)
+
+	calls = nil
+	err = NewDefault(3, time.Millisecond, opt).Do(context.Background(), func() error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(calls), "not called on success")
+	assert.Equal(t, "context", ReasonContext.String())
+}
+
+func TestRepeaterSetStrategy(t *testing.T) {
+	var calls []time.Time
+	fun := func() error {
+		calls = append(calls, time.Now())
+		return errors.New("some error")
+	}
+	gaps := func() (res []time.Duration) {
+		for i := 1; i < len(calls); i++ {
+			res = append(res, calls[i].Sub(calls[i-1]))
+		}
+		return res
+	}
+
+	r := NewDefault(4, 20*time.Millisecond)
+	require.Error(t, r.Do(context.Background(), fun))
+	require.Equal(t, 4, len(calls))
+	for _, d := range gaps() {
+		assert.True(t, d >= 20*time.Millisecond, "fixed delay %s", d)
+	}
+
+	calls = nil
+	r.SetStrategy(&strategy.Backoff{Duration: 20 * time.Millisecond, Repeats: 4, Factor: 3})
+	require.Error(t, r.Do(context.Background(), fun))
+	require.Equal(t, 4, len(calls))
+	g := gaps()
+	assert.True(t, g[2] >= 180*time.Millisecond, "backoff delay %s", g[2]) // 20ms * 3^2
+	assert.True(t, g[2] > g[1] && g[1] > g[0], "growing delays %v", g)
+
+	r.SetStrategy(nil)
+	assert.Equal(t, &strategy.FixedDelay{Repeats: 10, Delay: time.Second * 5}, r.Strategy)
+}
+
+func TestRepeaterLifetimeStats(t *testing.T) {
+	e := errors.New("some error")
+	r := NewDefault(3, time.Millisecond)
+	assert.Equal(t, LifetimeStats{}, r.LifetimeStats())
+	assert.Equal(t, 0.0, r.RetryRate(), "no calls made")
+
+	called := 0
+	require.NoError(t, r.Do(context.Background(), func() error {
+		called++
+		if called == 2 {
+			return nil
+		}
+		return e
+	}))
+	assert.Equal(t, LifetimeStats{TotalAttempts: 2, TotalRuns: 1, TotalSuccess: 1}, r.LifetimeStats())
+	assert.InDelta(t, 1.0, r.RetryRate(), 0.001)
+
+	require.Error(t, r.Do(context.Background(), func() error { return e }))
+	assert.Equal(t, LifetimeStats{TotalAttempts: 5, TotalRuns: 2, TotalSuccess: 1, TotalFailures: 1}, r.LifetimeStats())
+	assert.InDelta(t, 1.5, r.RetryRate(), 0.001)
+
+	require.NoError(t, r.Do(context.Background(), func() error { return nil }))
+	assert.Equal(t, LifetimeStats{TotalAttempts: 6, TotalRuns: 3, TotalSuccess: 2, TotalFailures: 1}, r.LifetimeStats())
+	assert.InDelta(t, 1.0, r.RetryRate(), 0.001, "3 retries per 3 calls")
+
+	r2 := Repeater{Strategy: &strategy.Once{}}
+	require.NoError(t, r2.Do(context.Background(), func() error { return nil }))
+	assert.Equal(t, LifetimeStats{}, r2.LifetimeStats(), "no stats for repeater made without New")
+	assert.Equal(t, 0.0, r2.RetryRate())
+}
+
+func TestDoValueN(t *testing.T) {
+	e := errors.New("some error")
+	called, val := 0, 0
+	fun := func() (int, error) {
+		called++
+		if called == 2 { // second call fails once
+			return 0, e
+		}
+		val++
+		return val * 10, nil
+	}
+
+	r := NewDefault(3, time.Millisecond)
+	res, err := DoValueN(context.Background(), r, 3, fun)
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 20, 30}, res)
+	assert.Equal(t, 4, called)
+	assert.Equal(t, int64(4), r.LifetimeStats().TotalAttempts)
+
+	called = 0
+	res, err = DoValueN(context.Background(), r, 3, func() (int, error) {
+		called++
+		if called > 1 {
+			return 0, e
+		}
+		return 1, nil
+	})
+	assert.Equal(t, e, err)
+	assert.Equal(t, []int{1}, res, "partial result")
+	assert.Equal(t, 4, called)
+}
+
+type budgetMock struct {
+	allowed, calls int
+}
+
+func (b *budgetMock) Withdraw() bool {
+	b.calls++
+	return b.calls <= b.allowed
+}
+
+func TestRepeaterBudget(t *testing.T) {
+	e := errors.New("some error")
+	called := 0
+	fun := func() error {
+		called++
+		return e
+	}
+
+	budget := &budgetMock{allowed: 1}
+	err := NewDefault(10, time.Millisecond, WithBudget(budget)).Do(context.Background(), fun)
+	assert.ErrorIs(t, err, ErrBudgetExhausted)
+	assert.ErrorIs(t, err, e)
+	assert.Equal(t, 2, called, "first attempt and one retry")
+	assert.Equal(t, 2, budget.calls, "not called for the first attempt")
+
+	called = 0
+	budget = &budgetMock{allowed: 0}
+	err = NewDefault(10, time.Millisecond, WithBudget(budget)).Do(context.Background(), func() error {
+		called++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, called)
+	assert.Equal(t, 0, budget.calls, "no retries, no withdraws")
+}
+
+func TestRepeaterSchedule(t *testing.T) {
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, NewDefault(3, time.Second).Schedule())
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		New(&strategy.Backoff{Duration: time.Second, Repeats: 5, Factor: 2}).Schedule())
+	assert.Equal(t, []time.Duration{}, New(&strategy.Once{}).Schedule())
+	assert.Equal(t, []time.Duration{time.Second}, New(&strategy.Ticker{Repeats: 2, Interval: time.Second}).Schedule())
+	assert.Nil(t, New(&strategyMock{}).Schedule(), "strategy without schedule")
+}
+
+type strategyMock struct{}
+
+func (s *strategyMock) Start(_ context.Context) <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+func TestRepeaterShouldRetry(t *testing.T) {
+	errTimeout := errors.New("timeout")
+	errOther := errors.New("other error")
+	criticalErr := errors.New("critical error")
+	opt := WithShouldRetry(func(attempt int, err error) bool {
+		return !errors.Is(err, errTimeout) || attempt < 2
+	})
+
+	called := 0
+	err := NewDefault(10, time.Millisecond, opt).Do(context.Background(), func() error {
+		called++
+		return errTimeout
+	})
+	assert.Equal(t, errTimeout, err)
+	assert.Equal(t, 2, called, "timeouts retried for the first two attempts only")
+
+	called = 0
+	err = NewDefault(5, time.Millisecond, opt).Do(context.Background(), func() error {
+		called++
+		return errOther
+	})
+	assert.Equal(t, errOther, err)
+	assert.Equal(t, 5, called, "other errors retried")
+
+	called = 0
+	err = NewDefault(5, time.Millisecond, WithShouldRetry(func(int, error) bool { return true })).Do(context.Background(),
+		func() error {
+			called++
+			return criticalErr
+		}, criticalErr)
+	assert.Equal(t, criticalErr, err)
+	assert.Equal(t, 1, called, "critical error wins")
+}
+
+func TestRepeaterDoChan(t *testing.T) {
+	e := errors.New("some error")
+	called := 0
+	fun := func() error {
+		called++
+		if called == 3 {
+			return nil
+		}
+		return e
+	}
+
+	var events []AttemptEvent
+	for ev := range NewDefault(5, 10*time.Millisecond).DoChan(context.Background(), fun) {
+		events = append(events, ev)
+	}
+	assert.Equal(t, []AttemptEvent{
+		{Attempt: 1, Err: e, NextDelay: 10 * time.Millisecond},
+		{Attempt: 2, Err: e, NextDelay: 10 * time.Millisecond},
+		{Attempt: 3, Final: true},
+	}, events)
+
+	events = nil
+	for ev := range NewDefault(2, time.Millisecond).DoChan(context.Background(), func() error { return e }) {
+		events = append(events, ev)
+	}
+	assert.Equal(t, []AttemptEvent{
+		{Attempt: 1, Err: e, NextDelay: time.Millisecond},
+		{Attempt: 2, Err: e},
+		{Attempt: 2, Err: e, Final: true},
+	}, events)
+}
+
+func TestRepeaterDoChanPipeline(t *testing.T) {
+	e := errors.New("some error")
+	probes, called := 0, 0
+	r := NewDefault(5, time.Millisecond, WithSuccessErrors(io.EOF),
+		WithErrorTransform(func(err error) error { return fmt.Errorf("transformed: %w", err) }),
+		WithProbe(func(context.Context) error {
+			probes++
+			if probes == 1 {
+				return e // fun skipped
+			}
+			return nil
+		}))
+	var events []AttemptEvent
+	for ev := range r.DoChan(context.Background(), func() error {
+		called++
+		if called == 1 {
+			return e
+		}
+		return io.EOF
+	}) {
+		events = append(events, ev)
+	}
+	require.Equal(t, 3, len(events), "success error is not a failed attempt")
+	assert.Equal(t, 1, events[0].Attempt)
+	assert.EqualError(t, events[0].Err, "transformed: some error")
+	assert.Equal(t, 2, events[1].Attempt, "attempts counted by Do, not by calls of fun")
+	assert.EqualError(t, events[1].Err, "transformed: some error")
+	assert.Equal(t, AttemptEvent{Attempt: 3, Final: true}, events[2])
+	assert.Equal(t, 2, called)
+}
+
+func TestRepeaterDoChanAbandoned(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var called int32
+	ch := NewDefault(100, time.Millisecond).DoChan(ctx, func() error {
+		atomic.AddInt32(&called, 1)
+		return errors.New("some error")
+	})
+	<-ch // consumer reads one event and stops
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	n := atomic.LoadInt32(&called)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt32(&called), "Do stopped by context")
+	select {
+	case _, ok := <-ch:
+		if ok { // at most one event left by the send racing with cancellation
+			_, ok = <-ch
+		}
+		assert.False(t, ok, "channel closed without consumer")
+	case <-time.After(time.Second):
+		t.Fatal("goroutine blocked on send")
+	}
+}
+
+func TestRepeaterResettableStrategy(t *testing.T) {
+	strtg := &statefulStrategyMock{limit: 3}
+	r := New(strtg)
+	called := 0
+	fun := func() error {
+		called++
+		return errors.New("some error")
+	}
+
+	require.Error(t, r.Do(context.Background(), fun))
+	assert.Equal(t, 3, called)
+	assert.Equal(t, 1, strtg.resets)
+
+	called = 0
+	require.Error(t, r.Do(context.Background(), fun))
+	assert.Equal(t, 3, called, "state reset, all ticks available again")
+	assert.Equal(t, 2, strtg.resets)
+}
+
+// statefulStrategyMock sends up to limit ticks in total, across all runs
+type statefulStrategyMock struct {
+	limit, sent, resets int
+}
+
+func (s *statefulStrategyMock) Reset() {
+	s.sent = 0
+	s.resets++
+}
+
+func (s *statefulStrategyMock) Start(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		defer close(ch)
+		for ; s.sent < s.limit; s.sent++ {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- struct{}{}:
+			}
+		}
+	}()
+	return ch
+}
+
+func TestRepeaterEagerFirstAttempt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := 0
+	fun := func() error {
+		called++
+		return errors.New("some error")
+	}
+
+	err := NewDefault(5, time.Millisecond, WithEagerFirstAttempt()).Do(ctx, fun)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, called, "called once despite canceled context")
+
+	called = 0
+	err = NewDefault(5, time.Millisecond, WithEagerFirstAttempt()).Do(ctx, func() error {
+		called++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, called)
+
+	called = 0
+	err = NewDefault(3, time.Millisecond, WithEagerFirstAttempt()).Do(context.Background(), fun)
+	assert.EqualError(t, err, "some error")
+	assert.Equal(t, 3, called, "regular retries with active context")
+
+	called = 0
+	tctx, tcancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer tcancel()
+	err = New(&silentStrategyMock{}, WithEagerFirstAttempt()).Do(tctx, fun)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, called, "called once, context done while waiting for the first tick")
+
+	called = 0
+	limit := &atomic.Int64{}
+	err = NewDefault(5, time.Millisecond, WithEagerFirstAttempt(), WithSharedAttemptLimit(limit)).Do(ctx, fun)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, called, "called once despite exhausted shared limit")
+	assert.Equal(t, int64(-1), limit.Load(), "attempt counted by the limit")
+}
+
+// silentStrategyMock never ticks, closes the channel on context done
+type silentStrategyMock struct{}
+
+func (s *silentStrategyMock) Start(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch
+}
+
+func TestRepeaterMaxDistinctErrors(t *testing.T) {
+	called := 0
+	fun := func() error {
+		called++
+		return fmt.Errorf("error %d", called)
+	}
+	err := NewDefault(10, time.Millisecond, WithMaxDistinctErrors(3)).Do(context.Background(), fun)
+	assert.ErrorIs(t, err, ErrTooManyDistinctErrors)
+	assert.Contains(t, err.Error(), "error 4")
+	assert.Equal(t, 4, called, "stopped on the 4th distinct error")
+
+	called = 0
+	err = NewDefault(5, time.Millisecond, WithMaxDistinctErrors(1)).Do(context.Background(), func() error {
+		called++
+		return errors.New("same error")
+	})
+	assert.EqualError(t, err, "same error")
+	assert.Equal(t, 5, called, "repeated error counted once")
+}
+
+func TestRepeaterDoDrain(t *testing.T) {
+	var queue []string
+	pop := func() (bool, error) {
+		if len(queue) == 0 {
+			return false, nil
+		}
+		queue = queue[1:]
+		return true, nil
+	}
+
+	queue, called := []string{"a", "b"}, 0
+	err := NewDefault(10, time.Millisecond).DoDrain(context.Background(), func() (bool, error) {
+		called++
+		return pop()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, called, "two calls with work and one to see nothing left")
+	assert.Empty(t, queue)
+
+	e := errors.New("some error")
+	queue, called = []string{"a", "b"}, 0
+	err = NewDefault(10, time.Millisecond).DoDrain(context.Background(), func() (bool, error) {
+		called++
+		if called == 2 {
+			return false, e // failed attempt retried
+		}
+		return pop()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, called)
+
+	queue, called = []string{"a", "b"}, 0
+	err = NewDefault(10, time.Millisecond).DoDrain(context.Background(), func() (bool, error) {
+		called++
+		if called == 2 {
+			return false, e
+		}
+		return pop()
+	}, e)
+	assert.Equal(t, e, err, "critical error terminates")
+	assert.Equal(t, 2, called)
+
+	queue, called = []string{"a", "b", "c", "d", "e"}, 0
+	var reasons []GiveUpReason
+	err = NewDefault(3, time.Millisecond, WithOnGiveUp(func(reason GiveUpReason, err error) {
+		assert.Equal(t, ErrNotDrained, err)
+		reasons = append(reasons, reason)
+	})).DoDrain(context.Background(), func() (bool, error) {
+		called++
+		return pop()
+	})
+	assert.Equal(t, ErrNotDrained, err, "attempts exhausted with work left")
+	assert.Equal(t, 3, called)
+	assert.Equal(t, []string{"d", "e"}, queue)
+	assert.Equal(t, []GiveUpReason{ReasonExhausted}, reasons)
+}
+
+func TestRepeaterMaxWorkDuration(t *testing.T) {
+	called := 0
+	fun := func() error {
+		called++
+		if called == 1 {
+			time.Sleep(30 * time.Millisecond) // slow, but successful
+		}
+		return nil
+	}
+	err := NewDefault(5, time.Millisecond, WithMaxWorkDuration(10*time.Millisecond)).Do(context.Background(), fun)
+	require.NoError(t, err)
+	assert.Equal(t, 2, called, "slow success retried")
+
+	called = 0
+	err = NewDefault(2, time.Millisecond, WithMaxWorkDuration(10*time.Millisecond)).Do(context.Background(), func() error {
+		called++
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err, "slow success on the final attempt is a success")
+	assert.Equal(t, 2, called)
+
+	called = 0
+	err = NewDefault(5, time.Millisecond, WithMaxWorkDuration(10*time.Millisecond)).Do(context.Background(), func() error {
+		called++
+		time.Sleep(20 * time.Millisecond)
+		if called == 1 {
+			return nil
+		}
+		return errors.New("some error")
+	})
+	assert.EqualError(t, err, "some error")
+	assert.Equal(t, 5, called)
+}
+
+func TestRepeaterMaxWorkDurationStopped(t *testing.T) {
+	slowFun := func(called *int) func() error {
+		return func() error {
+			*called++
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}
+	}
+	var hooks []string
+	opts := []Option{
+		WithMaxWorkDuration(10 * time.Millisecond),
+		WithOnGiveUp(func(GiveUpReason, error) { hooks = append(hooks, "give up") }),
+		WithOnFirstFailure(func(int, error) { hooks = append(hooks, "first failure") }),
+		WithCleanup(func(int, error) { hooks = append(hooks, "cleanup") }),
+		WithShouldRetry(func(int, error) bool { hooks = append(hooks, "should retry"); return true }),
+	}
+
+	called := 0
+	budget := &budgetMock{allowed: 1}
+	err := NewDefault(5, time.Millisecond, append(opts, WithBudget(budget))...).Do(context.Background(), slowFun(&called))
+	require.NoError(t, err, "budget exhausted, slow success is a success")
+	assert.Equal(t, 2, called, "one retry allowed by budget")
+	assert.Equal(t, 2, budget.calls)
+
+	called = 0
+	limit := &atomic.Int64{}
+	limit.Store(1)
+	err = NewDefault(5, time.Millisecond, append(opts, WithSharedAttemptLimit(limit))...).Do(context.Background(), slowFun(&called))
+	require.NoError(t, err, "shared limit reached, slow success is a success")
+	assert.Equal(t, 1, called)
+
+	called = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = NewDefault(5, time.Second, opts...).Do(ctx, func() error {
+		called++
+		time.Sleep(20 * time.Millisecond)
+		time.AfterFunc(10*time.Millisecond, cancel) // cancel during the delay before retry
+		return nil
+	})
+	require.NoError(t, err, "context canceled, slow success is a success")
+	assert.Equal(t, 1, called)
+	assert.Empty(t, hooks, "slow success is not a failure")
+}
+
+func TestRepeaterAttemptCountInError(t *testing.T) {
+	e := errors.New("some error")
+	var hookErr error
+	r := NewDefault(3, time.Millisecond, WithAttemptCountInError(), WithOnGiveUp(func(_ GiveUpReason, err error) {
+		hookErr = err
+	}))
+	err := r.Do(context.Background(), func() error { return fmt.Errorf("wrapped: %w", e) })
+	assert.EqualError(t, err, "after 3 attempts: wrapped: some error")
+	assert.ErrorIs(t, err, e)
+	assert.Equal(t, err, hookErr, "hook gets the same error")
+
+	err = r.Do(context.Background(), func() error { return nil })
+	assert.NoError(t, err)
+
+	err = NewDefault(3, time.Millisecond).Do(context.Background(), func() error { return e })
+	assert.EqualError(t, err, "some error", "not wrapped by default")
+}
+
+func TestRepeaterMinInterval(t *testing.T) {
+	var calls []time.Time
+	fun := func() error {
+		calls = append(calls, time.Now())
+		return errors.New("some error")
+	}
+	err := NewDefault(5, 0, WithMinInterval(5*time.Millisecond)).Do(context.Background(), fun)
+	require.Error(t, err)
+	require.Equal(t, 5, len(calls))
+	for i := 1; i < len(calls); i++ {
+		assert.True(t, calls[i].Sub(calls[i-1]) >= 5*time.Millisecond, "spacing %s", calls[i].Sub(calls[i-1]))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	calls = nil
+	err = NewDefault(5, 0, WithMinInterval(time.Second)).Do(ctx, fun)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, len(calls))
+}
+
+func TestRepeaterDoDefault(t *testing.T) {
+	called := 0
+	fun := func() error {
+		called++
+		return errors.New("some error")
+	}
+	err := NewDefault(3, time.Millisecond).DoDefault(fun)
+	assert.EqualError(t, err, "some error")
+	assert.Equal(t, 3, called)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	called = 0
+	st := time.Now()
+	err = NewDefault(10, time.Second, WithDefaultContext(ctx)).DoDefault(fun)
+	require.Error(t, err)
+	assert.Equal(t, 1, called)
+	assert.True(t, time.Since(st) < time.Second, "stopped by default context")
+}
+
+func TestRepeaterDoAllocs(t *testing.T) {
+	e := errors.New("some error")
+	r := New(&strategy.FixedDelay{Repeats: 3})
+	// derived context, strategy's goroutine and channel, nothing per attempt
+	allocs := testing.AllocsPerRun(100, func() { _ = r.Do(context.Background(), func() error { return nil }) })
+	assert.LessOrEqual(t, allocs, 5.0, "success on the first try")
+	allocs = testing.AllocsPerRun(100, func() { _ = r.Do(context.Background(), func() error { return e }) })
+	assert.LessOrEqual(t, allocs, 5.0, "all attempts failed")
+}
+
+func BenchmarkRepeaterDo(b *testing.B) {
+	e := errors.New("some error")
+	r := New(&strategy.FixedDelay{Repeats: 3})
+
+	b.Run("success first try", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = r.Do(context.Background(), func() error { return nil })
+		}
+	})
+
+	b.Run("fail all", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = r.Do(context.Background(), func() error { return e })
+		}
+	})
+}
+
+type wrapperErr struct {
+	err error
+}
+
+func (w wrapperErr) Error() string { return "wrapper: " + w.err.Error() }
+
+func TestRepeaterErrorTransform(t *testing.T) {
+	criticalErr := errors.New("critical error")
+	unwrap := WithErrorTransform(func(err error) error {
+		if w, ok := err.(wrapperErr); ok {
+			return w.err
+		}
+		return err
+	})
+
+	called := 0
+	fun := func() error {
+		called++
+		return wrapperErr{err: criticalErr} // hides critical error from errors.Is
+	}
+
+	err := NewDefault(5, time.Millisecond).Do(context.Background(), fun, criticalErr)
+	assert.Equal(t, wrapperErr{err: criticalErr}, err)
+	assert.Equal(t, 5, called, "critical error not matched without transform")
+
+	called = 0
+	err = NewDefault(5, time.Millisecond, unwrap).Do(context.Background(), fun, criticalErr)
+	assert.Equal(t, criticalErr, err, "transformed error returned")
+	assert.Equal(t, 1, called, "critical error matched after transform")
+}
+
+func TestRepeaterDoParallel(t *testing.T) {
+	var active, maxActive int32
+	var mu sync.Mutex
+	calls := make([]int, 10)
+	funcs := make([]func() error, 10)
+	for i := range funcs {
+		i := i
+		funcs[i] = func() error {
+			n := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			calls[i]++
+			switch {
+			case i%3 == 0 && calls[i] < 3: // needs retries
+				return fmt.Errorf("error %d", i)
+			case i == 7: // always fails
+				return fmt.Errorf("error %d", i)
+			}
+			return nil
+		}
+	}
+
+	r := NewDefault(4, time.Millisecond)
+	res := r.DoParallel(context.Background(), 3, funcs)
+	require.Equal(t, 10, len(res))
+	for i, err := range res {
+		if i == 7 {
+			assert.EqualError(t, err, "error 7")
+			assert.Equal(t, 4, calls[i])
+			continue
+		}
+		assert.NoError(t, err, "func %d", i)
+		if i%3 == 0 {
+			assert.Equal(t, 3, calls[i], "func %d retried", i)
+		}
+	}
+	assert.True(t, atomic.LoadInt32(&maxActive) <= 3, "max active %d", maxActive)
+	assert.Equal(t, int64(10), r.LifetimeStats().TotalRuns)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res = r.DoParallel(ctx, 3, funcs)
+	for _, err := range res {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestRepeaterDescribe(t *testing.T) {
+	tbl := []struct {
+		strtg strategy.Interface
+		res   string
+	}{
+		{&strategy.FixedDelay{Repeats: 10, Delay: 5 * time.Second}, "fixed delay=5s repeats=10"},
+		{&strategy.FixedDelay{Delay: time.Millisecond}, "fixed delay=1ms repeats=1"},
+		{&strategy.Backoff{Duration: time.Second, Repeats: 5, Factor: 2}, "backoff duration=1s factor=2 repeats=5 jitter=off"},
+		{&strategy.Backoff{Duration: time.Second, Repeats: 5, Factor: 1.5, Jitter: true}, "backoff duration=1s factor=1.5 repeats=5 jitter=on"},
+		{&strategy.Backoff{Jitter: true, MaxJitter: 10 * time.Millisecond}, "backoff duration=100ms factor=1 repeats=1 jitter=max 10ms"},
+		{&strategy.Once{}, "once"},
+		{&strategy.Ticker{Repeats: 3, Interval: time.Second}, "ticker interval=1s repeats=3"},
+		{&strategyMock{}, "custom strategy *repeater.strategyMock"},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.res, New(tt.strtg).Describe())
+	}
+}
+
+func TestRepeaterCanceledWithTickReady(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		strtg := &cancelingStrategyMock{cancel: cancel, called: make(chan struct{}, 10), ready: make(chan struct{})}
+		calls := 0
+		err := New(strtg).Do(ctx, func() error {
+			calls++
+			strtg.called <- struct{}{}
+			<-strtg.ready // both next tick and context done are ready on return
+			return errors.New("some error")
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, calls, "no attempt after cancellation, iteration %d", i)
+		cancel()
+	}
+}
+
+// cancelingStrategyMock sends the first tick, then, once called, cancels context and sends the second tick
+type cancelingStrategyMock struct {
+	cancel context.CancelFunc
+	called chan struct{}
+	ready  chan struct{}
+}
+
+func (s *cancelingStrategyMock) Start(_ context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	go func() {
+		ch <- struct{}{}
+		<-s.called
+		s.cancel()
+		ch <- struct{}{} // buffered, doesn't block
+		close(s.ready)
+	}()
+	return ch
+}
+
+func TestRepeaterCanceledWithChannelClosed(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		strtg := &closingStrategyMock{cancel: cancel, called: make(chan struct{}, 10), ready: make(chan struct{})}
+		var reason GiveUpReason
+		err := New(strtg, WithOnGiveUp(func(r GiveUpReason, _ error) { reason = r })).Do(ctx, func() error {
+			strtg.called <- struct{}{}
+			<-strtg.ready // both closed channel and context done are ready on return
+			return errors.New("some error")
+		})
+		require.ErrorIs(t, err, context.Canceled, "iteration %d", i)
+		require.Equal(t, ReasonContext, reason)
+		cancel()
+	}
+}
+
+// closingStrategyMock sends the first tick, then, once called, cancels context and closes the channel
+type closingStrategyMock struct {
+	cancel context.CancelFunc
+	called chan struct{}
+	ready  chan struct{}
+}
+
+func (s *closingStrategyMock) Start(_ context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	go func() {
+		ch <- struct{}{}
+		<-s.called
+		s.cancel()
+		close(ch)
+		close(s.ready)
+	}()
+	return ch
+}
+
+func TestRepeaterMaxConsecutiveFailures(t *testing.T) {
+	e := errors.New("some error")
+	run := func(pattern string) (called int, err error) {
+		return drainPattern(NewDefault(100, time.Millisecond, WithMaxConsecutiveFailures(3)), pattern, e)
+	}
+
+	called, err := run("ffwffwffwd")
+	require.NoError(t, err, "successes reset the counter")
+	assert.Equal(t, 10, called)
+
+	called, err = run("ffwfffwd")
+	assert.ErrorIs(t, err, ErrTooManyFailures)
+	assert.ErrorIs(t, err, e)
+	assert.Equal(t, 6, called, "stopped on the third consecutive failure")
+
+	called = 0
+	err = NewDefault(10, time.Millisecond, WithMaxConsecutiveFailures(3)).Do(context.Background(), func() error {
+		called++
+		return e
+	})
+	assert.ErrorIs(t, err, ErrTooManyFailures)
+	assert.Equal(t, 3, called)
+}
+
+// drainPattern runs DoDrain with calls results defined by pattern: f - failure with e, w - work done, d - drained
+func drainPattern(r *Repeater, pattern string, e error) (called int, err error) {
+	err = r.DoDrain(context.Background(), func() (bool, error) {
+		called++
+		switch pattern[called-1] {
+		case 'f':
+			return false, e
+		case 'w':
+			return true, nil
+		}
+		return false, nil
+	})
+	return called, err
+}
+
+func TestRepeaterStrategyFunc(t *testing.T) {
+	var calls []time.Time
+	fun := func() error {
+		calls = append(calls, time.Now())
+		return errors.New("some error")
+	}
+
+	r := New(strategy.Func(func(attempt int) time.Duration {
+		if attempt >= 4 {
+			return -1 // no more attempts
+		}
+		return time.Duration(attempt) * 20 * time.Millisecond
+	}))
+	require.Error(t, r.Do(context.Background(), fun))
+	require.Equal(t, 4, len(calls))
+	for i := 1; i < len(calls); i++ {
+		gap := calls[i].Sub(calls[i-1])
+		expected := time.Duration(i) * 20 * time.Millisecond
+		assert.True(t, gap >= expected, "gap %d is %s, expected at least %s", i, gap, expected)
+	}
+}
+
+type readCloserMock struct {
+	io.Reader
+	closed bool
+}
+
+func (r *readCloserMock) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestDoFactory(t *testing.T) {
+	var made []*readCloserMock
+	create := func() (*readCloserMock, error) {
+		rc := &readCloserMock{Reader: strings.NewReader("some data")}
+		made = append(made, rc)
+		return rc, nil
+	}
+	used := 0
+	use := func(rc *readCloserMock) error {
+		used++
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "some data", string(data), "fresh reader for each attempt")
+		if used < 3 {
+			return errors.New("some error")
+		}
+		return nil
+	}
+
+	err := DoFactory(context.Background(), NewDefault(5, time.Millisecond), create, use)
+	require.NoError(t, err)
+	assert.Equal(t, 3, used)
+	require.Equal(t, 3, len(made))
+	for _, rc := range made {
+		assert.True(t, rc.closed)
+	}
+
+	e := errors.New("create error")
+	err = DoFactory(context.Background(), NewDefault(3, time.Millisecond),
+		func() (io.Reader, error) { return nil, e },
+		func(io.Reader) error { t.Fatal("should not be called"); return nil })
+	assert.Equal(t, e, err)
+}
+
+func TestRepeaterDoTimeout(t *testing.T) {
+	called := 0
+	fun := func() error {
+		called++
+		return errors.New("some error")
+	}
+
+	st := time.Now()
+	err := NewDefault(100, 20*time.Millisecond).DoTimeout(context.Background(), 50*time.Millisecond, fun)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(st) >= 50*time.Millisecond, "took %s", time.Since(st))
+	assert.True(t, called >= 1 && called <= 3, "called %d times, stopped by timeout long before 100 attempts", called)
+
+	parent, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = NewDefault(100, 20*time.Millisecond).DoTimeout(parent, time.Second, fun)
+	assert.ErrorIs(t, err, context.Canceled, "parent context respected")
+}
+
+func TestDoResult(t *testing.T) {
+	type response struct {
+		status string
+	}
+	called := 0
+	fun := func() (response, error) {
+		called++
+		if called < 3 {
+			return response{status: "pending"}, nil
+		}
+		return response{status: "done"}, nil
+	}
+	pending := func(r response) bool { return r.status == "pending" }
+
+	res, err := DoResult(context.Background(), NewDefault(5, time.Millisecond), fun, pending)
+	require.NoError(t, err)
+	assert.Equal(t, response{status: "done"}, res)
+	assert.Equal(t, 3, called)
+
+	called = 0
+	res, err = DoResult(context.Background(), NewDefault(2, time.Millisecond), fun, pending)
+	assert.ErrorIs(t, err, ErrResultRejected)
+	assert.Equal(t, response{status: "pending"}, res, "last result returned")
+	assert.Equal(t, 2, called)
+
+	e := errors.New("some error")
+	called = 0
+	_, err = DoResult(context.Background(), NewDefault(5, time.Millisecond), func() (response, error) {
+		called++
+		return response{}, e
+	}, pending, e)
+	assert.Equal(t, e, err)
+	assert.Equal(t, 1, called, "critical error terminates")
+}
+
+func TestRepeaterZeroDelayCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var called int32
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	st := time.Now()
+	err := NewDefault(math.MaxInt32, 0).Do(ctx, func() error {
+		atomic.AddInt32(&called, 1)
+		return errors.New("some error")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, time.Since(st) < time.Second, "canceled promptly, took %s", time.Since(st)) // generous bound, MaxInt32 attempts otherwise
+	assert.True(t, atomic.LoadInt32(&called) > 1)
+}
+
+func TestRepeaterOnRetryDecide(t *testing.T) {
+	e := errors.New("some error")
+	criticalErr := errors.New("critical error")
+	var hookCalls []int
+	hook := WithOnRetryDecide(func(attempt int, err error) bool {
+		hookCalls = append(hookCalls, attempt)
+		return attempt < 2
+	})
+
+	called := 0
+	err := NewDefault(5, time.Millisecond, hook).Do(context.Background(), func() error {
+		called++
+		return e
+	})
+	assert.Equal(t, e, err)
+	assert.Equal(t, 2, called, "stopped by hook on attempt 2")
+	assert.Equal(t, []int{1, 2}, hookCalls)
+
+	hookCalls, called = nil, 0
+	err = NewDefault(5, time.Millisecond, hook).Do(context.Background(), func() error {
+		called++
+		return criticalErr
+	}, criticalErr)
+	assert.Equal(t, criticalErr, err)
+	assert.Equal(t, 1, called)
+	assert.Empty(t, hookCalls, "not called for critical error")
+
+	hookCalls, called = nil, 0
+	err = NewDefault(5, time.Millisecond, hook, WithShouldRetry(func(int, error) bool { return false })).Do(
+		context.Background(), func() error {
+			called++
+			return e
+		})
+	assert.Equal(t, e, err)
+	assert.Equal(t, 1, called)
+	assert.Empty(t, hookCalls, "not called if should retry stopped")
+}
+
+func TestRepeaterDefaults(t *testing.T) {
+	r := New(nil)
+	assert.Equal(t, &strategy.FixedDelay{Repeats: DefaultRepeats, Delay: DefaultDelay}, r.Strategy)
+	assert.Equal(t, 10, DefaultRepeats)
+	assert.Equal(t, 5*time.Second, DefaultDelay)
+}
+
+func TestDoEvery(t *testing.T) {
+	var starts []time.Duration
+	st := time.Now()
+	err := DoEvery(context.Background(), 50*time.Millisecond, 3, func() error {
+		starts = append(starts, time.Since(st))
+		time.Sleep(70 * time.Millisecond) // overruns the interval
+		return errors.New("some error")
+	})
+	require.Error(t, err)
+	require.Equal(t, 3, len(starts))
+	for i := 1; i < len(starts); i++ {
+		gap, offset := starts[i]-starts[i-1], starts[i]-starts[0]
+		assert.True(t, gap >= 70*time.Millisecond, "attempt %d after %s, started before the previous one ended", i, gap)
+		phase := offset % (50 * time.Millisecond) // distance to the nearest slot, ticks can be a bit early or late
+		if phase > 25*time.Millisecond {
+			phase = 50*time.Millisecond - phase
+		}
+		assert.True(t, phase < 20*time.Millisecond, "attempt %d started at %s, not aligned to the cadence", i, offset)
+	}
+
+	called := 0
+	err = DoEvery(context.Background(), 10*time.Millisecond, 5, func() error {
+		called++
+		if called == 2 {
+			return nil
+		}
+		return errors.New("some error")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, called)
+}
+
+func TestRepeaterMinSuccessRate(t *testing.T) {
+	e := errors.New("some error")
+	run := func(pattern string) (called int, err error) {
+		return drainPattern(NewDefault(100, time.Millisecond, WithMinSuccessRate(0.5, 4)), pattern, e)
+	}
+
+	called, err := run("fwfwfwwfwd")
+	require.NoError(t, err, "rate never below 0.5 in a window of 4")
+	assert.Equal(t, 10, called)
+
+	called, err = run("fwfwfwfffwd")
+	assert.ErrorIs(t, err, ErrSuccessRateTooLow)
+	assert.ErrorIs(t, err, e)
+	assert.Equal(t, 8, called, "window wfff has rate 0.25")
+
+	called, err = run("fffwd")
+	require.NoError(t, err, "not checked till window is full")
+	assert.Equal(t, 5, called)
+}
+
+func TestEscalate(t *testing.T) {
+	called := 0
+	fun := func() error {
+		called++
+		if called < 5 {
+			return fmt.Errorf("error %d", called)
+		}
+		return nil
+	}
+
+	fast, slow := NewDefault(3, time.Millisecond), NewDefault(5, 10*time.Millisecond)
+	err := Escalate(context.Background(), fun, fast, slow)
+	require.NoError(t, err)
+	assert.Equal(t, 5, called, "3 fast attempts and 2 slow ones")
+	assert.Equal(t, LifetimeStats{TotalAttempts: 3, TotalRuns: 1, TotalFailures: 1}, fast.LifetimeStats())
+	assert.Equal(t, LifetimeStats{TotalAttempts: 2, TotalRuns: 1, TotalSuccess: 1}, slow.LifetimeStats())
+
+	called = 0
+	err = Escalate(context.Background(), fun, NewDefault(1, time.Millisecond), NewDefault(2, time.Millisecond))
+	assert.EqualError(t, err, "error 1\nerror 3")
+	assert.Equal(t, 3, called)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Escalate(ctx, fun, fast, slow)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(2), fast.LifetimeStats().TotalRuns)
+	assert.Equal(t, int64(1), slow.LifetimeStats().TotalRuns, "not escalated on canceled context")
+}
+
+func TestRepeaterDoWithFallback(t *testing.T) {
+	e := errors.New("some error")
+	criticalErr := errors.New("critical error")
+	primaryCalls, fallbackCalls := 0, 0
+	primary := func() error {
+		primaryCalls++
+		return e
+	}
+	fallback := func() error {
+		fallbackCalls++
+		return nil
+	}
+
+	var reasons []GiveUpReason
+	r := NewDefault(3, time.Millisecond, WithOnGiveUp(func(reason GiveUpReason, _ error) { reasons = append(reasons, reason) }))
+	err := r.DoWithFallback(context.Background(), primary, fallback)
+	require.NoError(t, err, "fallback succeeded")
+	assert.Equal(t, 3, primaryCalls, "primary attempted the full count")
+	assert.Equal(t, 1, fallbackCalls)
+	assert.Equal(t, []GiveUpReason{ReasonExhausted}, reasons, "user's hook still called")
+
+	primaryCalls, fallbackCalls = 0, 0
+	err = r.DoWithFallback(context.Background(), func() error { primaryCalls++; return criticalErr }, fallback, criticalErr)
+	assert.Equal(t, criticalErr, err)
+	assert.Equal(t, 1, primaryCalls)
+	assert.Equal(t, 0, fallbackCalls, "no fallback on critical error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = r.DoWithFallback(ctx, primary, fallback)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, fallbackCalls, "no fallback on context termination")
+
+	err = r.DoWithFallback(context.Background(), func() error { return nil }, fallback)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, fallbackCalls, "no fallback on success")
+}
+
+func TestRepeaterOnFirstFailure(t *testing.T) {
+	type failure struct {
+		attempt int
+		err     error
+	}
+	var failures []failure
+	r := NewDefault(5, time.Millisecond, WithOnFirstFailure(func(attempt int, err error) {
+		failures = append(failures, failure{attempt: attempt, err: err})
+	}))
+
+	called := 0
+	err := r.Do(context.Background(), func() error {
+		called++
+		if called < 4 {
+			return fmt.Errorf("error %d", called)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(failures), "fired once")
+	assert.Equal(t, 1, failures[0].attempt)
+	assert.EqualError(t, failures[0].err, "error 1")
+
+	failures = nil
+	require.NoError(t, r.Do(context.Background(), func() error { return nil }))
+	assert.Empty(t, failures, "not fired on success")
+
+	failures, called = nil, 0
+	err = r.DoDrain(context.Background(), func() (bool, error) {
+		called++
+		switch called {
+		case 1:
+			return true, nil
+		case 2, 3:
+			return false, fmt.Errorf("error %d", called)
+		}
+		return false, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(failures), "fired once per run")
+	assert.Equal(t, 2, failures[0].attempt)
+}
+
+func TestRepeaterContextErrorWrap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	r := NewDefault(10, 10*time.Millisecond, WithContextErrorWrap(), WithAttemptCountInError())
+	err := r.Do(ctx, func() error {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return errors.New("some error")
+	})
+	require.Error(t, err)
+	assert.Regexp(t, `^canceled after 2 attempts \(\d+ms\): context canceled$`, err.Error())
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 15*time.Millisecond)
+	defer cancel()
+	err = r.Do(ctx, func() error { return errors.New("some error") })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Regexp(t, `^timed out after \d+ attempts \(\d+ms\): context deadline exceeded$`, err.Error())
+
+	err = r.Do(context.Background(), func() error { return errors.New("some error") })
+	assert.EqualError(t, err, "after 10 attempts: some error", "non-context errors not wrapped by the option")
+
+	for i := 0; i < 100; i++ { // strategy closed its channel on cancellation, closed channel and context done both ready
+		ctx, cancel := context.WithCancel(context.Background())
+		strtg := &closingStrategyMock{cancel: cancel, called: make(chan struct{}, 10), ready: make(chan struct{})}
+		err = New(strtg, WithContextErrorWrap()).Do(ctx, func() error {
+			strtg.called <- struct{}{}
+			<-strtg.ready
+			return errors.New("some error")
+		})
+		require.Regexp(t, `^canceled after 1 attempts \(\d+m?s\): context canceled$`, err.Error(), "iteration %d", i)
+		cancel()
+	}
+}
+
+func TestRepeaterGate(t *testing.T) {
+	gate := &gateMock{open: make(chan struct{})}
+	r := NewDefault(5, time.Millisecond, WithGate(gate))
+	var called int32
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Do(context.Background(), func() error {
+			if atomic.AddInt32(&called, 1) < 3 {
+				return errors.New("some error")
+			}
+			return nil
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&called), "paused by closed gate")
+	close(gate.open)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("not resumed")
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&called))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&gate.waits), "waited before each attempt")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := r.Do(ctx, func() error { return nil })
+	assert.NoError(t, err, "open gate doesn't block")
+
+	gate = &gateMock{open: make(chan struct{})}
+	var reason GiveUpReason
+	r = NewDefault(5, time.Millisecond, WithGate(gate), WithOnGiveUp(func(rs GiveUpReason, _ error) { reason = rs }))
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = r.Do(ctx, func() error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "context done while paused")
+	assert.Equal(t, ReasonContext, reason)
+}
+
+type gateMock struct {
+	open  chan struct{}
+	waits int32
+}
+
+func (g *gateMock) Wait(ctx context.Context) error {
+	atomic.AddInt32(&g.waits, 1)
+	select {
+	case <-g.open:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestRepeaterDeadlineBound(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	called := 0
+	st := time.Now()
+	err := NewDeadlineBound(5*time.Millisecond).Do(ctx, func() error {
+		called++
+		return errors.New("some error")
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(st) >= 100*time.Millisecond, "retried till the deadline")
+	assert.True(t, called > 10, "more attempts than default repeats, %d", called)
+
+	called = 0
+	err = NewDeadlineBound(time.Millisecond).Do(context.Background(), func() error {
+		called++
+		if called < 50 {
+			return errors.New("some error")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 50, called)
+}
+
+func TestDoOnTick(t *testing.T) {
+	tick := make(chan time.Time)
+	attempted := make(chan int, 10)
+	done := make(chan error, 1)
+	go func() {
+		called := 0
+		done <- DoOnTick(context.Background(), tick, 5, func() error {
+			called++
+			attempted <- called
+			if called < 3 {
+				return errors.New("some error")
+			}
+			return nil
+		})
+	}()
+
+	assert.Equal(t, 1, <-attempted, "the first attempt right away")
+	for i := 2; i <= 3; i++ {
+		time.Sleep(20 * time.Millisecond)
+		select {
+		case n := <-attempted:
+			t.Fatalf("attempt %d made without tick", n)
+		default:
+		}
+		tick <- time.Now()
+		assert.Equal(t, i, <-attempted, "attempt on tick")
+	}
+	require.NoError(t, <-done)
+
+	close(tick)
+	err := DoOnTick(context.Background(), tick, 5, func() error { return errors.New("some error") })
+	assert.EqualError(t, err, "some error", "closed tick channel stops repeats")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = DoOnTick(ctx, make(chan time.Time), 5, func() error { return errors.New("some error") })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDoHedged(t *testing.T) {
+	var calls, canceled int32
+	st := time.Now()
+	err := DoHedged(context.Background(), 3, 20*time.Millisecond, func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) == 1 { // the first call is slow
+			select {
+			case <-ctx.Done():
+				atomic.AddInt32(&canceled, 1)
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return nil
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, time.Since(st) < 500*time.Millisecond, "returned on the second call, took %s", time.Since(st))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "third call not needed")
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&canceled) == 1 }, time.Second, time.Millisecond,
+		"slow call canceled")
+
+	calls = 0
+	err = DoHedged(context.Background(), 3, time.Second, func(context.Context) error {
+		return fmt.Errorf("error %d", atomic.AddInt32(&calls, 1))
+	})
+	assert.EqualError(t, err, "error 1\nerror 2\nerror 3", "failed call launches the next one without waiting")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = DoHedged(ctx, 2, 5*time.Millisecond, func(context.Context) error {
+		time.Sleep(time.Second) // ignores context, abandoned
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRepeaterSuccessErrors(t *testing.T) {
+	r := NewDefault(5, time.Millisecond, WithSuccessErrors(io.EOF))
+	called := 0
+	err := r.Do(context.Background(), func() error {
+		called++
+		return fmt.Errorf("read: %w", io.EOF)
+	}, io.EOF)
+	assert.NoError(t, err, "success error takes precedence over critical one")
+	assert.Equal(t, 1, called)
+
+	called = 0
+	err = r.Do(context.Background(), func() error {
+		called++
+		return errors.New("some error")
+	})
+	assert.EqualError(t, err, "some error")
+	assert.Equal(t, 5, called, "other errors retried")
+
+	called = 0
+	err = r.DoDrain(context.Background(), func() (bool, error) {
+		called++
+		if called == 3 {
+			return true, io.EOF
+		}
+		return true, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, called, "success error drains")
+}
+
+func TestWrap(t *testing.T) {
+	called := 0
+	fn := Wrap(NewDefault(3, time.Millisecond), context.Background(), func() error {
+		called++
+		return errors.New("some error")
+	})
+	assert.Equal(t, 0, called, "not called on wrapping")
+	assert.EqualError(t, fn(), "some error")
+	assert.Equal(t, 3, called)
+	assert.EqualError(t, fn(), "some error")
+	assert.Equal(t, 6, called, "each call repeats")
+
+	called = 0
+	fn = Wrap(NewDefault(3, time.Millisecond), context.Background(), func() error {
+		called++
+		return io.EOF
+	}, io.EOF)
+	assert.Equal(t, io.EOF, fn())
+	assert.Equal(t, 1, called, "critical error terminates")
+}
+
+func TestRepeaterCleanup(t *testing.T) {
+	type cleaned struct {
+		attempt int
+		err     error
+	}
+	var res []cleaned
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := NewDefault(5, 50*time.Millisecond, WithCleanup(func(attempt int, err error) { res = append(res, cleaned{attempt, err}) }))
+	called := 0
+	err := r.Do(ctx, func() error {
+		called++
+		if called == 2 {
+			time.AfterFunc(10*time.Millisecond, cancel) // cancel during the next delay
+		}
+		return fmt.Errorf("error %d", called)
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 2, len(res), "fired for each failed attempt")
+	assert.Equal(t, 2, res[1].attempt, "fired for the last attempt before canceled delay")
+	assert.EqualError(t, res[1].err, "error 2")
+
+	res, called = nil, 0
+	err = NewDefault(5, time.Millisecond, WithCleanup(func(attempt int, err error) { res = append(res, cleaned{attempt, err}) })).
+		Do(context.Background(), func() error {
+			called++
+			if called == 3 {
+				return nil
+			}
+			return errors.New("some error")
+		})
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(res), "not fired for successful attempt")
+}
+
+func TestRepeaterRateLimited(t *testing.T) {
+	var starts []time.Duration
+	st := time.Now()
+	err := NewRateLimited(5, 20).Do(context.Background(), func() error {
+		starts = append(starts, time.Since(st))
+		time.Sleep(10 * time.Millisecond) // attempt's time counts toward the interval
+		return errors.New("some error")
+	})
+	require.Error(t, err)
+	require.Equal(t, 5, len(starts))
+	for i := 1; i < len(starts); i++ {
+		offset := starts[i] - starts[0]
+		assert.True(t, offset >= time.Duration(i)*50*time.Millisecond-10*time.Millisecond,
+			"attempt %d started at %s, faster than the rate", i, offset)
+		phase := offset % (50 * time.Millisecond) // distance to the nearest slot, ticks can be a bit early or late
+		if phase > 25*time.Millisecond {
+			phase = 50*time.Millisecond - phase
+		}
+		assert.True(t, phase < 20*time.Millisecond, "attempt %d started at %s, not aligned to the cadence", i, offset)
+	}
+
+	assert.Equal(t, "ticker interval=20ms repeats=6", NewRateLimited(6, 50).Describe())
+	assert.Equal(t, "ticker interval=0s repeats=3", NewRateLimited(3, 0).Describe(), "no pacing")
+}
+
+func TestRepeaterRetryError(t *testing.T) {
+	e := errors.New("some error")
+	called := 0
+	r := NewDefault(3, time.Millisecond, WithRetryError())
+	err := r.Do(context.Background(), func() error {
+		called++
+		return fmt.Errorf("error %d: %w", called, e)
+	})
+	var re *RetryError
+	require.True(t, errors.As(err, &re))
+	assert.Equal(t, 3, re.Attempts)
+	require.Equal(t, 3, len(re.Errors))
+	for i, ae := range re.Errors {
+		assert.EqualError(t, ae, fmt.Sprintf("error %d: some error", i+1))
+	}
+	assert.True(t, re.Duration >= 2*time.Millisecond, "duration %s", re.Duration)
+	assert.Regexp(t, `^3 attempts failed in \d+ms: error 3: some error$`, err.Error())
+	assert.ErrorIs(t, err, e, "the last error wrapped")
+
+	err = r.Do(context.Background(), func() error { return io.EOF }, io.EOF)
+	assert.Equal(t, io.EOF, err, "critical error returned as is")
+
+	err = NewDefault(5, time.Millisecond, WithRetryError(), WithMaxConsecutiveFailures(2)).
+		Do(context.Background(), func() error { return e })
+	require.True(t, errors.As(err, &re), "exhausted by limit")
+	assert.Equal(t, 2, re.Attempts)
+	assert.ErrorIs(t, err, ErrTooManyFailures)
+}
+
+func TestRepeaterProbe(t *testing.T) {
+	probes, called := 0, 0
+	r := NewDefault(5, time.Millisecond, WithProbe(func(ctx context.Context) error {
+		require.NoError(t, ctx.Err())
+		probes++
+		if probes < 3 {
+			return fmt.Errorf("probe %d failed", probes)
+		}
+		return nil
+	}))
+	err := r.Do(context.Background(), func() error {
+		called++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, probes)
+	assert.Equal(t, 1, called, "fun skipped while probe fails")
+
+	probes, called = 0, 0
+	err = NewDefault(2, time.Millisecond, WithProbe(func(context.Context) error {
+		probes++
+		return errors.New("probe failed")
+	})).Do(context.Background(), func() error {
+		called++
+		return nil
+	})
+	assert.EqualError(t, err, "probe failed", "probe's error is the attempt's error")
+	assert.Equal(t, 2, probes)
+	assert.Equal(t, 0, called)
+}
+
+func TestRepeaterDoAsync(t *testing.T) {
+	r := NewDefault(5, 10*time.Millisecond)
+	var called int32
+	firstErr, done := r.DoAsync(context.Background(), func() error {
+		if n := atomic.AddInt32(&called, 1); n < 3 {
+			return fmt.Errorf("error %d", n)
+		}
+		return nil
+	})
+	assert.EqualError(t, firstErr, "error 1", "the first error returned synchronously")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&called), "retries in background")
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("no final result")
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&called))
+	_, ok := <-done
+	assert.False(t, ok, "closed after the result")
+
+	called = 0
+	firstErr, done = r.DoAsync(context.Background(), func() error {
+		atomic.AddInt32(&called, 1)
+		return io.EOF
+	}, io.EOF)
+	assert.Equal(t, io.EOF, firstErr)
+	assert.Equal(t, io.EOF, <-done, "critical error, no retries")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&called))
+
+	firstErr, done = r.DoAsync(context.Background(), func() error { return nil })
+	assert.NoError(t, firstErr)
+	assert.NoError(t, <-done)
+	assert.Equal(t, LifetimeStats{TotalAttempts: 5, TotalRuns: 3, TotalSuccess: 2, TotalFailures: 1}, r.LifetimeStats(),
+		"inline attempt counted once")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	firstErr, done = r.DoAsync(ctx, func() error { return nil })
+	assert.ErrorIs(t, firstErr, context.Canceled, "done context, as Do")
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestRepeaterDoAsyncPipeline(t *testing.T) {
+	var called int32
+	r := NewDefault(5, time.Millisecond, WithSuccessErrors(io.EOF), WithErrorTransform(func(err error) error {
+		return fmt.Errorf("transformed: %w", err)
+	}))
+	firstErr, done := r.DoAsync(context.Background(), func() error {
+		atomic.AddInt32(&called, 1)
+		return io.EOF
+	})
+	assert.NoError(t, firstErr, "success error is a success for the first attempt too")
+	assert.NoError(t, <-done)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&called), "no retries")
+
+	called = 0
+	e := errors.New("some error")
+	firstErr, done = r.DoAsync(context.Background(), func() error {
+		atomic.AddInt32(&called, 1)
+		return e
+	}, e)
+	assert.EqualError(t, firstErr, "transformed: some error", "transformed error returned")
+	assert.EqualError(t, <-done, "transformed: some error", "critical after transformation")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&called))
+
+	limit := &atomic.Int64{}
+	firstErr, done = NewDefault(5, time.Millisecond, WithSharedAttemptLimit(limit)).DoAsync(context.Background(), func() error {
+		t.Fatal("must not be called")
+		return nil
+	})
+	assert.ErrorIs(t, firstErr, ErrSharedLimitReached, "limit checked before the first attempt")
+	assert.ErrorIs(t, <-done, ErrSharedLimitReached)
+}
+
+func TestRepeaterSharedAttemptLimit(t *testing.T) {
+	limit := &atomic.Int64{}
+	limit.Store(7)
+	var called int32
+	fun := func() error {
+		atomic.AddInt32(&called, 1)
+		return errors.New("some error")
+	}
+
+	var wg sync.WaitGroup
+	res := make([]error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := NewDefault(5, time.Millisecond, WithSharedAttemptLimit(limit))
+			res[i] = r.Do(context.Background(), fun)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(7), atomic.LoadInt32(&called), "combined attempts limited")
+	limited := 0
+	for _, err := range res {
+		require.Error(t, err)
+		if errors.Is(err, ErrSharedLimitReached) {
+			limited++
+			assert.ErrorContains(t, err, "some error", "joined with the last error")
+		}
+	}
+	assert.True(t, limited > 0, "stopped by limit before 10 attempts")
+
+	err := NewDefault(5, time.Millisecond, WithSharedAttemptLimit(limit)).Do(context.Background(), fun)
+	assert.ErrorIs(t, err, ErrSharedLimitReached)
+	assert.EqualError(t, err, "shared attempt limit reached", "no attempts left")
+	assert.Equal(t, int32(7), atomic.LoadInt32(&called))
+}
+
+func TestRepeaterSkipInitialContextCheck(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	time.Sleep(5 * time.Millisecond) // expired before Do
+
+	called := 0
+	var reason GiveUpReason
+	r := NewDefault(5, time.Millisecond, WithSkipInitialContextCheck(), WithOnGiveUp(func(rs GiveUpReason, _ error) { reason = rs }))
+	err := r.Do(ctx, func() error {
+		called++
+		return errors.New("some error")
+	})
+	assert.EqualError(t, err, "some error", "the attempt's error returned")
+	assert.Equal(t, 1, called, "exactly one attempt with expired context")
+	assert.Equal(t, ReasonExhausted, reason, "not a context error")
+
+	called = 0
+	err = r.Do(ctx, func() error {
+		called++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, called)
+
+	called = 0
+	err = r.Do(context.Background(), func() error {
+		called++
+		return errors.New("some error")
+	})
+	assert.EqualError(t, err, "some error")
+	assert.Equal(t, 5, called, "regular retries with active context")
+
+	called = 0
+	limit := &atomic.Int64{}
+	r = NewDefault(5, time.Millisecond, WithSkipInitialContextCheck(), WithSharedAttemptLimit(limit))
+	err = r.Do(ctx, func() error {
+		called++
+		return errors.New("some error")
+	})
+	assert.EqualError(t, err, "some error", "not ErrSharedLimitReached")
+	assert.Equal(t, 1, called, "called once despite exhausted shared limit")
+}
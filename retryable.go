@@ -0,0 +1,26 @@
+package repeater
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// DefaultRetryable reports whether err is one of commonly transient errors: net.Error with timeout,
+// context.DeadlineExceeded, io.ErrUnexpectedEOF, connection reset or refused. Wrapped errors recognized too.
+// It is conservative, anything else is not retryable. Can be used as a predicate for WithShouldRetry:
+//
+//	WithShouldRetry(func(_ int, err error) bool { return DefaultRetryable(err) })
+func DefaultRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,37 @@
+package repeater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRetryable(t *testing.T) {
+	tbl := []struct {
+		err  error
+		res  bool
+		name string
+	}{
+		{&net.DNSError{Err: "timeout", IsTimeout: true}, true, "net timeout"},
+		{context.DeadlineExceeded, true, "deadline"},
+		{fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), true, "wrapped unexpected EOF"},
+		{&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true, "reset"},
+		{syscall.ECONNREFUSED, true, "refused"},
+		{&net.DNSError{Err: "no such host", IsNotFound: true}, false, "net error without timeout"},
+		{errors.New("some error"), false, "unrelated"},
+		{context.Canceled, false, "canceled"},
+		{nil, false, "nil"},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.res, DefaultRetryable(tt.err))
+		})
+	}
+}
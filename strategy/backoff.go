@@ -2,20 +2,28 @@ package strategy
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"math/rand"
 	"sync"
 	"time"
 )
 
+// DefaultBackoffDuration is the initial delay of Backoff with no Duration set
+const DefaultBackoffDuration = 100 * time.Millisecond
+
 // Backoff implements strategy.Interface for exponential-backoff
-// it starts from 100ms (by default, if no Duration set) and goes in steps with last * math.Pow(factor, attempt)
-// optional jitter randomize intervals a little bit.
+// it starts from DefaultBackoffDuration (100ms, if no Duration set) and goes in steps with last * math.Pow(factor, attempt)
+// optional jitter randomize intervals a little bit, delay distributed uniformly in [delay-Duration, delay+Duration)
+// or [delay-MaxJitter, delay+MaxJitter) if MaxJitter set and less than Duration. Negative delays clamped to 0.
 type Backoff struct {
-	Duration time.Duration
-	Repeats  int
-	Factor   float64
-	Jitter   bool
+	Duration  time.Duration
+	Repeats   int
+	Factor    float64
+	Jitter    bool
+	MaxJitter time.Duration // caps absolute jitter, no cap if 0
+	Rounding  time.Duration // rounds delays, after jitter, to the nearest multiple, no rounding if 0
+	Offset    time.Duration // fixed base added to each exponential delay, before jitter and rounding
 
 	once sync.Once
 }
@@ -24,23 +32,14 @@ type Backoff struct {
 // then publishing signals to channel ch for retries attempt. Closed ch indicates "done" event
 // consumer (repeater) should stop it explicitly after completion
 func (b *Backoff) Start(ctx context.Context) <-chan struct{} {
-
-	b.once.Do(func() {
-		if b.Duration == 0 {
-			b.Duration = 100 * time.Millisecond
-		}
-		if b.Repeats == 0 {
-			b.Repeats = 1
-		}
-		if b.Factor <= 0 {
-			b.Factor = 1
-		}
-	})
-
+	b.init()
 	ch := make(chan struct{})
 	go func() {
 		defer close(ch)
-		rnd := rand.New(rand.NewSource(int64(time.Now().Nanosecond()))) //nolint:gosec
+		var rnd *rand.Rand // random source for jitter only, delays without jitter are deterministic
+		if b.Jitter {
+			rnd = rand.New(rand.NewSource(int64(time.Now().Nanosecond()))) //nolint:gosec
+		}
 		for i := 0; i < b.Repeats; i++ {
 			select {
 			case <-ctx.Done():
@@ -48,12 +47,79 @@ func (b *Backoff) Start(ctx context.Context) <-chan struct{} {
 			case ch <- struct{}{}:
 			}
 
-			delay := float64(b.Duration) * math.Pow(b.Factor, float64(i))
-			if b.Jitter {
-				delay = rnd.Float64()*(float64(2*b.Duration)) + (delay - float64(b.Duration))
-			}
-			sleep(ctx, time.Duration(delay))
+			sleep(ctx, b.delay(i, rnd))
 		}
 	}()
 	return ch
 }
+
+// Schedule returns nominal delays between attempts, i.e. without jitter
+func (b *Backoff) Schedule() []time.Duration {
+	b.init()
+	res := []time.Duration{}
+	for i := 0; i < b.Repeats-1; i++ {
+		res = append(res, b.duration(b.nominal(i)))
+	}
+	return res
+}
+
+// Describe returns human-readable configuration, like "backoff duration=1s factor=2 repeats=5 jitter=on"
+func (b *Backoff) Describe() string {
+	b.init()
+	jitter := "off"
+	switch {
+	case b.Jitter && b.MaxJitter > 0:
+		jitter = "max " + b.MaxJitter.String()
+	case b.Jitter:
+		jitter = "on"
+	}
+	res := fmt.Sprintf("backoff duration=%s factor=%g repeats=%d jitter=%s", b.Duration, b.Factor, b.Repeats, jitter)
+	if b.Offset != 0 {
+		res += " offset=" + b.Offset.String()
+	}
+	return res
+}
+
+// init sets defaults for unset fields, once
+func (b *Backoff) init() {
+	b.once.Do(func() {
+		if b.Duration == 0 {
+			b.Duration = DefaultBackoffDuration
+		}
+		if b.Repeats == 0 {
+			b.Repeats = 1
+		}
+		if b.Factor <= 0 || math.IsNaN(b.Factor) {
+			b.Factor = 1
+		}
+	})
+}
+
+// nominal returns delay after given attempt (0-based) without jitter, offset included
+func (b *Backoff) nominal(attempt int) float64 {
+	return float64(b.Offset) + float64(b.Duration)*math.Pow(b.Factor, float64(attempt))
+}
+
+// delay calculates delay after given attempt (0-based), never negative or overflowed. rnd used for jitter only and can be nil without it
+func (b *Backoff) delay(attempt int, rnd *rand.Rand) time.Duration {
+	delay := b.nominal(attempt)
+	if b.Jitter {
+		amplitude := b.Duration
+		if b.MaxJitter > 0 && b.MaxJitter < amplitude {
+			amplitude = b.MaxJitter
+		}
+		delay += (2*rnd.Float64() - 1) * float64(amplitude)
+	}
+	return b.duration(delay)
+}
+
+// duration converts delay to rounded duration, clamped to [0, math.MaxInt64] to prevent negative or overflowed delays
+func (b *Backoff) duration(delay float64) time.Duration {
+	switch {
+	case delay < 0:
+		return 0
+	case delay >= math.MaxInt64: // overflow with large factor or attempt
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay).Round(b.Rounding)
+}
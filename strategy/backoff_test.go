@@ -0,0 +1,157 @@
+package strategy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffMaxJitter(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+	b := Backoff{Duration: 60 * time.Second, Factor: 1, Jitter: true, MaxJitter: 2 * time.Second}
+	for i := 0; i < 1000; i++ {
+		d := b.delay(0, rnd)
+		assert.True(t, d >= 58*time.Second && d <= 62*time.Second, "delay %s", d)
+	}
+
+	// capped jitter is scaled, not clamped, so delays spread uniformly over the band instead of piling at its edges
+	const samples = 10000
+	var sum, sumSq float64
+	edges := 0
+	for i := 0; i < samples; i++ {
+		dev := float64(b.delay(0, rnd)-60*time.Second) / float64(time.Second)
+		sum += dev
+		sumSq += dev * dev
+		if math.Abs(dev) > 1.99 {
+			edges++
+		}
+	}
+	mean := sum / samples
+	assert.InDelta(t, 0, mean, 0.05, "mean deviation %.3fs", mean)
+	assert.InDelta(t, 4.0/3, sumSq/samples-mean*mean, 0.1, "variance of uniform distribution in ±2s") // a^2/3
+	assert.True(t, edges < samples/100, "%d of %d samples at the edges", edges, samples)
+
+	b = Backoff{Duration: 100 * time.Millisecond, Factor: 0.1, Jitter: true}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, b.delay(3, rnd) >= 0, "never negative")
+	}
+}
+
+func TestBackoffSchedule(t *testing.T) {
+	b := Backoff{Duration: time.Second, Repeats: 5, Factor: 2, Jitter: true}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}, b.Schedule())
+
+	b = Backoff{}
+	assert.Equal(t, []time.Duration{}, b.Schedule(), "single attempt by default")
+}
+
+func TestBackoffJitterDistribution(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42)) //nolint:gosec
+	b := Backoff{Duration: 100 * time.Millisecond, Factor: 2, Jitter: true}
+	nominal := 400 * time.Millisecond // attempt 2, 100ms * 2^2
+
+	const samples = 100000
+	var sum, lo, hi time.Duration = 0, nominal, nominal
+	for i := 0; i < samples; i++ {
+		d := b.delay(2, rnd)
+		assert.True(t, d >= nominal-b.Duration && d < nominal+b.Duration, "delay %s out of band", d)
+		sum += d
+		if d < lo {
+			lo = d
+		}
+		if d > hi {
+			hi = d
+		}
+	}
+	mean := sum / samples
+	assert.InDelta(t, float64(nominal), float64(mean), float64(time.Millisecond), "mean %s, no bias", mean)
+	assert.True(t, lo < nominal-99*time.Millisecond, "lower edge reached, %s", lo)
+	assert.True(t, hi > nominal+99*time.Millisecond, "upper edge reached, %s", hi)
+}
+
+func TestBackoffNoJitterDeterministic(t *testing.T) {
+	b := Backoff{Duration: 10 * time.Millisecond, Factor: 1.5}
+	first := []time.Duration{}
+	for i := 0; i < 10; i++ {
+		first = append(first, b.delay(i, nil)) // no random source needed without jitter
+	}
+	for run := 0; run < 10; run++ {
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, first[i], b.delay(i, nil))
+		}
+	}
+
+	// no jitter doesn't consume random draws, so seeded sequence of jittered strategy is not shifted
+	jittered := Backoff{Duration: 10 * time.Millisecond, Factor: 1.5, Jitter: true}
+	rnd1, rnd2 := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(1)) //nolint:gosec
+	for i := 0; i < 10; i++ {
+		b.delay(i, rnd2)
+		assert.Equal(t, jittered.delay(i, rnd1), jittered.delay(i, rnd2))
+	}
+}
+
+func TestBackoffDefaults(t *testing.T) {
+	b := Backoff{}
+	b.init()
+	assert.Equal(t, DefaultBackoffDuration, b.Duration)
+	assert.Equal(t, 1, b.Repeats)
+	assert.Equal(t, 1.0, b.Factor)
+}
+
+func TestBackoffRounding(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+	b := Backoff{Duration: 130 * time.Millisecond, Repeats: 5, Factor: 1.7, Jitter: true, Rounding: 100 * time.Millisecond}
+	for i := 0; i < 1000; i++ {
+		d := b.delay(i%5, rnd)
+		assert.Equal(t, time.Duration(0), d%(100*time.Millisecond), "delay %s not rounded", d)
+	}
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		600 * time.Millisecond}, b.Schedule()) // 130ms, 221ms, 375.7ms, 638.69ms
+}
+
+func TestBackoffInvalidConfig(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+	tbl := []struct {
+		name string
+		b    *Backoff
+	}{
+		{"negative factor", &Backoff{Duration: time.Millisecond, Factor: -0.5, Jitter: true}},
+		{"NaN factor", &Backoff{Duration: time.Millisecond, Factor: math.NaN(), Jitter: true}},
+		{"huge factor", &Backoff{Duration: time.Millisecond, Factor: 5.0e10, Jitter: true}},
+		{"infinite factor", &Backoff{Duration: time.Millisecond, Factor: math.Inf(1)}},
+		{"negative duration", &Backoff{Duration: -time.Second, Factor: 2, Jitter: true}},
+		{"negative max jitter", &Backoff{Duration: time.Second, Factor: 2, Jitter: true, MaxJitter: -time.Second}},
+	}
+	for _, tt := range tbl {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.b.init()
+			for i := 0; i < 100; i++ {
+				d := tt.b.delay(i%10, rnd)
+				assert.True(t, d >= 0, "delay %s is negative", d)
+			}
+		})
+	}
+
+	b := Backoff{Factor: math.NaN()}
+	b.init()
+	assert.Equal(t, 1.0, b.Factor, "NaN factor replaced by 1")
+}
+
+func TestBackoffOffset(t *testing.T) {
+	b := Backoff{Duration: 50 * time.Millisecond, Repeats: 4, Factor: 2, Offset: 100 * time.Millisecond}
+	assert.Equal(t, []time.Duration{150 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}, b.Schedule())
+	for i, expected := range b.Schedule() {
+		assert.Equal(t, expected, b.delay(i, nil), "delay after attempt %d", i)
+	}
+	assert.Equal(t, "backoff duration=50ms factor=2 repeats=4 jitter=off offset=100ms", b.Describe())
+
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+	b = Backoff{Duration: 50 * time.Millisecond, Repeats: 4, Factor: 2, Offset: 100 * time.Millisecond, Jitter: true}
+	for i := 0; i < 1000; i++ {
+		d := b.delay(0, rnd)
+		assert.True(t, d >= 100*time.Millisecond && d <= 200*time.Millisecond, "jitter applied on top of offset, %s", d)
+	}
+}
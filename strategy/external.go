@@ -0,0 +1,59 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExternalTicker implements strategy.Interface for attempts aligned to ticks of external channel, up to max repeats.
+// The first attempt goes right away, each next one on the next tick received from C. Like Ticker, a tick received
+// while the previous attempt still in progress is skipped. Closed C stops repeats.
+// Note: a tick is received by one reader only, C shared by concurrent calls should get a tick per call.
+type ExternalTicker struct {
+	Repeats int
+	C       <-chan time.Time
+}
+
+// Start returns channel publishing signals for retries attempts on ticks of C.
+// Closed ch indicates "done" event, either all repeats made, C closed or context canceled.
+func (s *ExternalTicker) Start(ctx context.Context) <-chan struct{} {
+	repeats := s.Repeats
+	if repeats == 0 {
+		repeats = 1
+	}
+	ch := make(chan struct{})
+	go func() {
+		defer close(ch)
+		select { // the first attempt right away
+		case <-ctx.Done():
+			return
+		case ch <- struct{}{}:
+		}
+		for sent := 1; sent < repeats; {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-s.C:
+				if !ok {
+					return
+				}
+			}
+			select {
+			case ch <- struct{}{}:
+				sent++
+			default: // previous attempt still in progress, skip this tick
+			}
+		}
+	}()
+	return ch
+}
+
+// Describe returns human-readable configuration, like "external ticker repeats=10"
+func (s *ExternalTicker) Describe() string {
+	repeats := s.Repeats
+	if repeats == 0 {
+		repeats = 1
+	}
+	return fmt.Sprintf("external ticker repeats=%d", repeats)
+}
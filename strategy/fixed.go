@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -15,15 +16,16 @@ type FixedDelay struct {
 // then publishing signals to channel ch for retries attempt.
 // can be terminated (canceled) via context.
 func (s *FixedDelay) Start(ctx context.Context) <-chan struct{} {
-	if s.Repeats == 0 {
-		s.Repeats = 1
+	repeats := s.Repeats // local copy, Start can be called concurrently
+	if repeats == 0 {
+		repeats = 1
 	}
 	ch := make(chan struct{})
 	go func() {
 		defer func() {
 			close(ch)
 		}()
-		for i := 0; i < s.Repeats; i++ {
+		for i := 0; i < repeats; i++ {
 			select {
 			case <-ctx.Done():
 				return
@@ -34,3 +36,21 @@ func (s *FixedDelay) Start(ctx context.Context) <-chan struct{} {
 	}()
 	return ch
 }
+
+// Schedule returns delays between attempts
+func (s *FixedDelay) Schedule() []time.Duration {
+	res := []time.Duration{}
+	for i := 1; i < s.Repeats; i++ {
+		res = append(res, s.Delay)
+	}
+	return res
+}
+
+// Describe returns human-readable configuration, like "fixed delay=5s repeats=10"
+func (s *FixedDelay) Describe() string {
+	repeats := s.Repeats
+	if repeats == 0 {
+		repeats = 1
+	}
+	return fmt.Sprintf("fixed delay=%s repeats=%d", s.Delay, repeats)
+}
@@ -0,0 +1,34 @@
+package strategy
+
+import (
+	"context"
+	"time"
+)
+
+// Func is an adapter to use an ordinary function as strategy, like http.HandlerFunc.
+// The function called right after an attempt started, with its 1-based number, to get the delay before the next one.
+// The delay counts from the attempt's start, as the attempt may still be in progress when the function called.
+// Negative delay stops repeats, otherwise attempts are not limited by the strategy.
+type Func func(attempt int) time.Duration
+
+// Start returns channel publishing signals for retries attempts, with delays defined by the function.
+// Closed ch indicates "done" event, either function returned negative delay or context canceled.
+func (f Func) Start(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		defer close(ch)
+		for attempt := 1; ; attempt++ {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- struct{}{}:
+			}
+			delay := f(attempt)
+			if delay < 0 {
+				return
+			}
+			sleep(ctx, delay)
+		}
+	}()
+	return ch
+}
@@ -12,6 +12,21 @@ type Interface interface {
 	Start(ctx context.Context) <-chan struct{}
 }
 
+// Scheduler is an optional interface for strategies able to report delays between attempts ahead of time
+type Scheduler interface {
+	Schedule() []time.Duration
+}
+
+// Resettable is an optional interface for stateful strategies. Repeater calls Reset at the beginning of each Do
+type Resettable interface {
+	Reset()
+}
+
+// Describer is an optional interface for strategies able to describe their configuration in human-readable form
+type Describer interface {
+	Describe() string
+}
+
 // Once strategy eliminate repeats and makes a single try only
 type Once struct{}
 
@@ -25,9 +40,26 @@ func (s *Once) Start(_ context.Context) <-chan struct{} {
 	return ch
 }
 
+// Schedule returns empty list, no delays as no repeats
+func (s *Once) Schedule() []time.Duration {
+	return []time.Duration{}
+}
+
+// Describe returns strategy name, no configuration for Once
+func (s *Once) Describe() string {
+	return "once"
+}
+
+// sleep waits for duration or context done. No timer made for zero duration as nothing to wait for,
+// context checked by the consumer of strategy's channel anyway.
 func sleep(ctx context.Context, duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
 	select {
-	case <-time.After(duration):
+	case <-timer.C:
 		return
 	case <-ctx.Done():
 		return
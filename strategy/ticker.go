@@ -0,0 +1,78 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Ticker implements strategy.Interface for attempts with a fixed cadence, up to max repeats.
+// Unlike FixedDelay, attempts aligned to start + n*Interval regardless of attempt's duration, and if an attempt
+// overran the next slot, this slot is skipped, like time.Ticker drops ticks for slow receivers.
+type Ticker struct {
+	Repeats  int
+	Interval time.Duration
+}
+
+// Start returns channel, similar to time.Ticker
+// then publishing signals to channel ch for retries attempt.
+// can be terminated (canceled) via context.
+func (s *Ticker) Start(ctx context.Context) <-chan struct{} {
+	repeats := s.Repeats
+	if repeats == 0 {
+		repeats = 1
+	}
+	ch := make(chan struct{})
+	go func() {
+		defer close(ch)
+		if s.Interval <= 0 { // no cadence, attempts go one by one
+			for i := 0; i < repeats; i++ {
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- struct{}{}:
+				}
+			}
+			return
+		}
+
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		select { // the first attempt right away
+		case <-ctx.Done():
+			return
+		case ch <- struct{}{}:
+		}
+		for sent := 1; sent < repeats; {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			select {
+			case ch <- struct{}{}:
+				sent++
+			default: // previous attempt still in progress, skip this slot
+			}
+		}
+	}()
+	return ch
+}
+
+// Schedule returns nominal intervals between attempts, slots skipped by slow attempts not counted
+func (s *Ticker) Schedule() []time.Duration {
+	res := []time.Duration{}
+	for i := 1; i < s.Repeats; i++ {
+		res = append(res, s.Interval)
+	}
+	return res
+}
+
+// Describe returns human-readable configuration, like "ticker interval=1s repeats=10"
+func (s *Ticker) Describe() string {
+	repeats := s.Repeats
+	if repeats == 0 {
+		repeats = 1
+	}
+	return fmt.Sprintf("ticker interval=%s repeats=%d", s.Interval, repeats)
+}